@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bohdan-natsevych/fsm-generator/pkg/fsm"
+)
+
+// definition is the JSON machine definition read by fsmgen. Symbols are
+// single runes written as one-character strings.
+type definition struct {
+	// Name prefixes the generated identifiers; it defaults to "Machine".
+	Name      string   `json:"name"`
+	Initial   string   `json:"initial"`
+	Accepting []string `json:"accepting"`
+	// States lists states without outgoing transitions; the others are
+	// taken from the transitions.
+	States      []string `json:"states"`
+	Transitions []struct {
+		From string `json:"from"`
+		On   string `json:"on"`
+		To   string `json:"to"`
+	} `json:"transitions"`
+}
+
+// generate returns the gofmt-formatted Go source of package pkg for the
+// definition in data, read from the file named source.
+func generate(data []byte, source, pkg string) ([]byte, error) {
+	var def definition
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if def.Name == "" {
+		def.Name = "Machine"
+	}
+	if !token.IsIdentifier(def.Name) {
+		return nil, fmt.Errorf("%s: name %q is not a Go identifier", source, def.Name)
+	}
+
+	if def.Initial == "" {
+		return nil, fmt.Errorf("%s: initial state is required", source)
+	}
+
+	table := make(map[string]map[rune]string)
+	for _, s := range def.States {
+		table[s] = map[rune]string{}
+	}
+	for i, t := range def.Transitions {
+		sym, size := utf8.DecodeRuneInString(t.On)
+		if size == 0 || size != len(t.On) {
+			return nil, fmt.Errorf("%s: transition %d: symbol %q is not a single rune", source, i, t.On)
+		}
+		if table[t.From] == nil {
+			table[t.From] = map[rune]string{}
+		}
+		if _, ok := table[t.From][sym]; ok {
+			return nil, fmt.Errorf("%s: transition %d: duplicate transition from %q on %q", source, i, t.From, t.On)
+		}
+		table[t.From][sym] = t.To
+	}
+	// Build once so an invalid definition fails here rather than at run time.
+	m, err := fsm.NewFromTable(def.Initial, def.Accepting, table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	typ := def.Name + "State"
+	consts := make(map[string]string)
+	used := make(map[string]string)
+	for _, s := range m.States() {
+		id := constName(def.Name, s)
+		if id == typ || id == "New"+def.Name {
+			return nil, fmt.Errorf("%s: state %q maps to %s, which is reserved", source, s, id)
+		}
+		if other, ok := used[id]; ok {
+			return nil, fmt.Errorf("%s: states %q and %q both map to %s", source, other, s, id)
+		}
+		used[id], consts[s] = s, id
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by fsmgen from %s; DO NOT EDIT.\n", source)
+	fmt.Fprintf(&buf, "// Source sha256: %x\n\n", sha256.Sum256(data))
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import %q\n\n", "github.com/bohdan-natsevych/fsm-generator/pkg/fsm")
+	fmt.Fprintf(&buf, "// %s is a state of the %s machine.\n", typ, def.Name)
+	fmt.Fprintf(&buf, "type %s string\n\n", typ)
+	fmt.Fprintf(&buf, "// States of the %s machine.\nconst (\n", def.Name)
+	for _, s := range m.States() {
+		fmt.Fprintf(&buf, "%s %s = %q\n", consts[s], typ, s)
+	}
+	fmt.Fprintf(&buf, ")\n\n")
+	fmt.Fprintf(&buf, "// New%s builds the %s machine with opts.\n", def.Name, def.Name)
+	fmt.Fprintf(&buf, "func New%s(opts ...fsm.Option) (*fsm.Machine[%s, rune], error) {\n", def.Name, typ)
+	var accepting []string
+	for _, s := range m.States() {
+		if m.Accepting(s) {
+			accepting = append(accepting, consts[s])
+		}
+	}
+	fmt.Fprintf(&buf, "return fsm.NewFromTable(%s, []%s{%s}, map[%s]map[rune]%s{\n", consts[m.InitialState()], typ, strings.Join(accepting, ", "), typ, typ)
+	for _, s := range m.States() {
+		var row []string
+		for t := range m.TransitionsFrom(s) {
+			row = append(row, fmt.Sprintf("%s: %s", strconv.QuoteRune(t.Symbol), consts[t.To]))
+		}
+		fmt.Fprintf(&buf, "%s: {%s},\n", consts[s], strings.Join(row, ", "))
+	}
+	fmt.Fprintf(&buf, "}, opts...)\n}\n")
+	return format.Source(buf.Bytes())
+}
+
+// constName returns the constant identifier for state: prefix followed by
+// the state name, capitalized, with characters invalid in identifiers
+// replaced by underscores.
+func constName(prefix, state string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i, r := range state {
+		switch {
+		case unicode.IsLetter(r) && i == 0:
+			b.WriteRune(unicode.ToUpper(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "mod3.fsm.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return data
+}
+
+func TestGenerateIsDeterministicAndFormatted(t *testing.T) {
+	data := readFixture(t)
+	first, err := generate(data, "mod3.fsm.json", "mod3")
+	if err != nil {
+		t.Fatalf("unexpected generate error: %v", err)
+	}
+	for range 5 {
+		again, err := generate(data, "mod3.fsm.json", "mod3")
+		if err != nil || !bytes.Equal(first, again) {
+			t.Fatalf("expected identical output on every run")
+		}
+	}
+	if formatted, err := format.Source(first); err != nil || !bytes.Equal(formatted, first) {
+		t.Fatalf("expected gofmt-clean output, got error %v", err)
+	}
+	header := fmt.Sprintf("// Code generated by fsmgen from mod3.fsm.json; DO NOT EDIT.\n// Source sha256: %x\n", sha256.Sum256(data))
+	if !strings.HasPrefix(string(first), header) {
+		t.Fatalf("expected header %q, got %q", header, first[:len(header)])
+	}
+	for _, want := range []string{"type Mod3State string", `Mod3S2 Mod3State = "S2"`, "func NewMod3("} {
+		if !bytes.Contains(first, []byte(want)) {
+			t.Errorf("expected %q in the output", want)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for want, def := range map[string]string{
+		`symbol "01" is not a single rune`:                   `{"initial": "A", "transitions": [{"from": "A", "on": "01", "to": "A"}]}`,
+		`states "a-b" and "a_b" both map to`:                 `{"initial": "a-b", "states": ["a_b"], "transitions": [{"from": "a-b", "on": "x", "to": "a-b"}]}`,
+		`maps to MachineState, which is reserved`:            `{"initial": "State", "transitions": [{"from": "State", "on": "x", "to": "State"}]}`,
+		`transition 1: duplicate transition from "A" on "x"`: `{"initial": "A", "transitions": [{"from": "A", "on": "x", "to": "A"}, {"from": "A", "on": "x", "to": "B"}]}`,
+		`initial state is required`:                          `{"transitions": [{"from": "A", "on": "x", "to": "A"}]}`,
+		`unknown field "intial"`:                             `{"intial": "A"}`,
+		`name "my machine" is not`:                           `{"name": "my machine"}`,
+	} {
+		if _, err := generate([]byte(def), "def.json", "p"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+// TestGeneratedCodeCompiles runs the generator on the fixture and runs the
+// output inside this module.
+func TestGeneratedCodeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles generated code")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	src, err := generate(readFixture(t), "mod3.fsm.json", "main")
+	if err != nil {
+		t.Fatalf("unexpected generate error: %v", err)
+	}
+	dir, err := os.MkdirTemp(".", "gen-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	mainSrc := `package main
+
+import "fmt"
+
+func main() {
+	m, err := NewMod3()
+	if err != nil {
+		panic(err)
+	}
+	state, err := m.Eval([]rune("1101"))
+	fmt.Println(state == Mod3S1, err)
+}
+`
+	for name, content := range map[string][]byte{"mod3_gen.go": src, "main.go": []byte(mainSrc)} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "true <nil>" {
+		t.Fatalf("generated code failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,46 @@
+// Command fsmgen generates Go code building a machine from a JSON definition,
+// for use with go:generate:
+//
+//	//go:generate fsmgen -def mod3.fsm.json -pkg mod3 -out mod3_gen.go
+//
+// The generated file holds typed state constants and a constructor, and its
+// header records the definition's SHA-256 so stale output can be detected.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	var def, pkg, out string
+	flag.StringVar(&def, "def", "", "JSON machine definition to read")
+	flag.StringVar(&pkg, "pkg", os.Getenv("GOPACKAGE"), "package of the generated file (default: $GOPACKAGE)")
+	flag.StringVar(&out, "out", "", "file to write (default: stdout)")
+	flag.Parse()
+
+	if def == "" || pkg == "" {
+		fmt.Fprintln(os.Stderr, "usage: fsmgen -def file.fsm.json -pkg name [-out file.go]")
+		os.Exit(2)
+	}
+	data, err := os.ReadFile(def)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "read error:", err)
+		os.Exit(2)
+	}
+	src, err := generate(data, filepath.Base(def), pkg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	if out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "write error:", err)
+		os.Exit(1)
+	}
+}
@@ -2,54 +2,47 @@ package mod3
 
 import (
 	"fmt"
-	"sync"
 
 	"github.com/bohdan-natsevych/fsm-generator/pkg/fsm"
 )
 
-var (
-	// Singleton pattern for better performance - avoid rebuilding FSM on each call
-	machine     *fsm.Machine[string, byte]
-	machineOnce sync.Once
-	machineErr  error
-)
+// Singleton pattern for better performance - avoid rebuilding FSM on each call
+var machine = fsm.NewLazy(Build)
 
 // Build constructs a modulo-3 FSM for binary input symbols '0' and '1'.
 // States represent the current remainder: S0=0, S1=1, S2=2.
 func Build() (*fsm.Machine[string, byte], error) {
-	b := fsm.NewBuilder[string, byte](
+	// δ per provided diagram/definition: row state, column input bit.
+	table := map[string]map[byte]string{
+		"S0": {'0': "S0", '1': "S1"},
+		"S1": {'0': "S2", '1': "S0"},
+		"S2": {'0': "S1", '1': "S2"},
+	}
+	// All states are accepting for modulo remainder output.
+	return fsm.NewFromTable("S0", []string{"S0", "S1", "S2"}, table,
 		fsm.WithPreventOverwriteTransitions(),
 		fsm.WithErrorOnUnreachableStates(),
 		fsm.WithErrorWhenNoAcceptingReachable(),
 	)
+}
 
-	// States and accepting set (all states are accepting for modulo remainder output)
-	b.AddState("S0", true)
-	b.AddState("S1", true)
-	b.AddState("S2", true)
-	b.SetInitial("S0")
-
-	// Symbols
-	b.AddSymbol('0')
-	b.AddSymbol('1')
-
-	// Transitions per provided diagram/definition
-	// δ(S0,0) = S0; δ(S0,1) = S1
-	b.On("S0", '0', "S0").On("S0", '1', "S1")
-	// δ(S1,0) = S2; δ(S1,1) = S0
-	b.On("S1", '0', "S2").On("S1", '1', "S0")
-	// δ(S2,0) = S1; δ(S2,1) = S2
-	b.On("S2", '0', "S1").On("S2", '1', "S2")
-
-	return b.Build()
+// BuildRunes constructs the same modulo-3 FSM over rune symbols.
+func BuildRunes() (*fsm.Machine[string, rune], error) {
+	m, err := Build()
+	if err != nil {
+		return nil, err
+	}
+	return fsm.MapSymbols(m, func(b byte) rune { return rune(b) })
 }
 
-// getMachine returns the singleton modulo-3 FSM instance, building it once.
-func getMachine() (*fsm.Machine[string, byte], error) {
-	machineOnce.Do(func() {
-		machine, machineErr = Build()
-	})
-	return machine, machineErr
+// Name is the name the machine is registered under in fsm.DefaultRegistry.
+// It is registered lazily, so importing the package never builds it.
+const Name = "mod3"
+
+func init() {
+	if err := fsm.Register(Name, machine); err != nil {
+		panic(err)
+	}
 }
 
 // ModThree returns the remainder in {0,1,2} for a binary string input.
@@ -67,7 +60,7 @@ func ModThree(binary string) (int, error) {
 		}
 	}
 	
-	m, err := getMachine()
+	m, err := machine.Get()
 	if err != nil {
 		return 0, err
 	}
@@ -1,6 +1,12 @@
 package mod3
 
-import "testing"
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/bohdan-natsevych/fsm-generator/pkg/fsm"
+)
 
 func TestModThreeKnownValues(t *testing.T) {
 	cases := map[string]int{
@@ -108,3 +114,102 @@ func TestModThreeValidationErrorMessages(t *testing.T) {
 }
 
 
+
+func TestBuildRunesMatchesBytes(t *testing.T) {
+	bm, err := Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	rm, err := BuildRunes()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	for _, in := range []string{"", "0", "1", "1101", "1110", "1111", "101010"} {
+		want, err := bm.Eval([]byte(in))
+		if err != nil {
+			t.Fatalf("unexpected eval error: %v", err)
+		}
+		got, err := rm.Eval([]rune(in))
+		if err != nil {
+			t.Fatalf("unexpected eval error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("%q => byte machine %v, rune machine %v", in, want, got)
+		}
+	}
+}
+
+func TestDivisibleByThreeDerivedMachine(t *testing.T) {
+	m, err := Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	div3, err := m.WithAccepting("S0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for n := 0; n < 64; n++ {
+		in := []byte(strconv.FormatInt(int64(n), 2))
+		ok, err := div3.EvalAccepting(in)
+		if err != nil {
+			t.Fatalf("unexpected eval error: %v", err)
+		}
+		if ok != (n%3 == 0) {
+			t.Fatalf("%d: expected accepting=%v, got %v", n, n%3 == 0, ok)
+		}
+		if all, _ := m.EvalAccepting(in); !all {
+			t.Fatalf("%d: original machine should still accept every input", n)
+		}
+	}
+}
+
+func TestBuildReportIsReachableAndComplete(t *testing.T) {
+	m, err := Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	_, report, err := m.ToBuilder().BuildWithReport()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !slices.Equal(report.Reachable, []string{"S0", "S1", "S2"}) {
+		t.Fatalf("expected every state reachable, got %v", report.Reachable)
+	}
+	if len(report.Unreachable) != 0 || len(report.Dead) != 0 || len(report.Missing) != 0 || !report.AcceptingReachable {
+		t.Fatalf("expected a complete machine without unreachable or dead states, got %+v", report)
+	}
+	if len(report.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", report.Warnings)
+	}
+}
+
+func TestRegisteredInDefaultRegistry(t *testing.T) {
+	m, err := fsm.Lookup[string, byte](fsm.DefaultRegistry, Name)
+	if err != nil {
+		t.Fatalf("unexpected lookup error: %v", err)
+	}
+	if state, _ := m.Eval([]byte("1111")); state != "S0" {
+		t.Fatalf("expected S0 for 1111, got %v", state)
+	}
+}
+
+// mod3Spec is the machine of Build in the declarative BuildFromSpec form.
+type mod3Spec struct {
+	S0     fsm.State `fsm:"initial,accepting"`
+	S1, S2 fsm.State `fsm:"accepting"`
+	Delta  struct{}  `fsm:"0:S0>S0,1:S0>S1,0:S1>S2,1:S1>S0,0:S2>S1,1:S2>S2"`
+}
+
+func TestSpecMatchesFluentDefinition(t *testing.T) {
+	spec, err := fsm.BuildFromSpec(mod3Spec{}, fsm.WithRequireTotalTransitions())
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	rm, err := BuildRunes()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !fsm.Equal(spec, rm) {
+		t.Fatalf("spec and fluent machines differ: %v", fsm.Diff(spec, rm))
+	}
+}
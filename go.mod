@@ -1,3 +1,5 @@
 module github.com/bohdan-natsevych/fsm-generator
 
-go 1.22.3
+go 1.23
+
+require gonum.org/v1/gonum v0.12.0
@@ -2,6 +2,9 @@ package fsm
 
 import (
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 )
 
 // Builder incrementally constructs a Machine.
@@ -13,6 +16,20 @@ type Builder[S comparable, Sym comparable] struct {
 	accepting    map[S]struct{}
 	transitions  map[TransitionKey[S, Sym]]S
 	options      buildOptions
+	// Default targets from OnElse, expanded by Build.
+	elseTargets map[S]S
+	// Set on the copy made by expand, which holds the fills.
+	expanded bool
+	// States only registered implicitly, and overwrites allowed by the
+	// options; both are reported as warnings.
+	implicit   map[S]struct{}
+	overwrites []Warning
+	// Set by a successful Build; see checkFrozen.
+	frozen bool
+	// Next candidate for NewState.
+	nextState S
+	// Errors found while defining the machine, reported by Build.
+	pending []error
 }
 
 // NewBuilder creates a new FSM builder.
@@ -22,50 +39,663 @@ func NewBuilder[S comparable, Sym comparable](opts ...Option) *Builder[S, Sym] {
 		symbols:     make(map[Sym]struct{}),
 		accepting:   make(map[S]struct{}),
 		transitions: make(map[TransitionKey[S, Sym]]S),
+		implicit:    make(map[S]struct{}),
 	}
 	for _, o := range opts {
 		o(&b.options)
 	}
+	if b.options.preventOverwriteTransitions && b.options.errorOnOverwriteTransitions {
+		panic("WithPreventOverwriteTransitions and WithErrorOnOverwriteTransitions are mutually exclusive")
+	}
+	b.checkOptionTypes()
+	return b
+}
+
+// checkOptionTypes records an error for every state given to a generic option
+// with a type other than the builder's state type, which would never match.
+func (b *Builder[S, Sym]) checkOptionTypes() {
+	if _, ok := b.options.completeSink.(S); b.options.completeWithSink && !ok {
+		b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "WithCompleteWithSink: sink %v is a %T, not a %v", b.options.completeSink, b.options.completeSink, reflect.TypeFor[S]()))
+	}
+	if _, ok := b.options.stateLess.(func(a, b S) bool); b.options.stateLess != nil && !ok {
+		b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "WithStateLess: comparator is a %T, not a func(a, b %v) bool", b.options.stateLess, reflect.TypeFor[S]()))
+	}
+	for _, s := range sortedKeys(b.options.terminalStatesAllowed) {
+		if _, ok := s.(S); !ok {
+			b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "WithErrorOnTerminalStates: exception %v is a %T, not a %v", s, s, reflect.TypeFor[S]()))
+		}
+	}
+}
+
+// ToBuilder returns a new Builder, configured with opts, holding the machine's
+// states, symbols, initial state, accepting set and transitions. The builder
+// owns copies of the data, so changing it never affects m.
+func (m *Machine[S, Sym]) ToBuilder(opts ...Option) *Builder[S, Sym] {
+	b := NewBuilder[S, Sym](opts...)
+	for _, s := range m.stateList {
+		b.states[s] = struct{}{}
+	}
+	for s := range m.accepting {
+		b.accepting[s] = struct{}{}
+	}
+	for sym := range m.symbols {
+		b.symbols[sym] = struct{}{}
+	}
+	for key, to := range m.transitions {
+		b.transitions[key] = to
+	}
+	b.initialSet = true
+	b.initialState = m.initialState
+	return b
+}
+
+// Clone returns a deep copy of the builder, including its options and pending
+// errors, so the copy and the original can diverge independently. The copy is
+// never frozen.
+func (b *Builder[S, Sym]) Clone() *Builder[S, Sym] {
+	c := &Builder[S, Sym]{
+		states:       maps.Clone(b.states),
+		symbols:      maps.Clone(b.symbols),
+		initialSet:   b.initialSet,
+		initialState: b.initialState,
+		accepting:    maps.Clone(b.accepting),
+		transitions:  maps.Clone(b.transitions),
+		options:      b.options,
+		elseTargets:  maps.Clone(b.elseTargets),
+		implicit:     maps.Clone(b.implicit),
+		overwrites:   slices.Clone(b.overwrites),
+		pending:      slices.Clone(b.pending),
+		nextState:    b.nextState,
+	}
+	c.options.terminalStatesAllowed = maps.Clone(b.options.terminalStatesAllowed)
+	return c
+}
+
+// Reset clears the definition and pending errors, keeping the options, and
+// unfreezes the builder.
+func (b *Builder[S, Sym]) Reset() *Builder[S, Sym] {
+	*b = *NewBuilder[S, Sym](func(o *buildOptions) { *o = b.options })
 	return b
 }
 
+// checkFrozen panics with ErrBuilderFrozen if a successful Build froze the
+// builder.
+func (b *Builder[S, Sym]) checkFrozen() {
+	if b.frozen {
+		panic(ErrBuilderFrozen)
+	}
+}
+
+// PendingErrors returns a copy of the errors recorded while defining the
+// machine, such as strict-registration violations and, under
+// WithEagerValidation, duplicate transitions. Build reports them together with
+// its own checks.
+func (b *Builder[S, Sym]) PendingErrors() []error {
+	return slices.Clone(b.pending)
+}
+
+// States returns the states declared so far in the package's deterministic
+// order. The slice is a copy.
+func (b *Builder[S, Sym]) States() []S {
+	return sortedKeys(b.states)
+}
+
+// Symbols returns the symbols declared so far in the package's deterministic
+// order. The slice is a copy.
+func (b *Builder[S, Sym]) Symbols() []Sym {
+	return sortedKeys(b.symbols)
+}
+
+// Initial returns the initial state and whether it has been set.
+func (b *Builder[S, Sym]) Initial() (S, bool) {
+	return b.initialState, b.initialSet
+}
+
+// IsAccepting reports whether state is currently in the accepting set.
+func (b *Builder[S, Sym]) IsAccepting(state S) bool {
+	_, ok := b.accepting[state]
+	return ok
+}
+
+// TransitionFor returns the explicitly defined target of from on sym, if any.
+// OnElse defaults are only expanded by Build and are not reported.
+func (b *Builder[S, Sym]) TransitionFor(from S, sym Sym) (S, bool) {
+	to, ok := b.transitions[TransitionKey[S, Sym]{From: from, Symbol: sym}]
+	return to, ok
+}
+
 // AddState registers a state. If isAccepting is true, it is added to the accepting set.
+// The flag is sticky: a later AddState(state, false) leaves the state accepting;
+// use SetAccepting to clear it.
 func (b *Builder[S, Sym]) AddState(state S, isAccepting bool) *Builder[S, Sym] {
+	b.checkFrozen()
 	b.states[state] = struct{}{}
+	delete(b.implicit, state)
 	if isAccepting {
 		b.accepting[state] = struct{}{}
 	}
 	return b
 }
 
+// SetAccepting registers state and makes it accepting or, unlike AddState,
+// non-accepting.
+func (b *Builder[S, Sym]) SetAccepting(state S, accepting bool) *Builder[S, Sym] {
+	b.checkFrozen()
+	b.states[state] = struct{}{}
+	delete(b.implicit, state)
+	if accepting {
+		b.accepting[state] = struct{}{}
+	} else {
+		delete(b.accepting, state)
+	}
+	return b
+}
+
+// AddStates registers several states, as repeated AddState calls with the same
+// accepting flag would.
+func (b *Builder[S, Sym]) AddStates(accepting bool, states ...S) *Builder[S, Sym] {
+	for _, s := range states {
+		b.AddState(s, accepting)
+	}
+	return b
+}
+
 // SetInitial sets the initial state. The state is implicitly registered.
 func (b *Builder[S, Sym]) SetInitial(state S) *Builder[S, Sym] {
+	b.checkFrozen()
+	b.checkRegistered([]S{state}, nil, "SetInitial(%v)", b.stateName(state))
 	b.initialSet = true
 	b.initialState = state
-	b.states[state] = struct{}{}
+	b.registerImplicit(state)
 	return b
 }
 
 // AddSymbol registers an input symbol.
 func (b *Builder[S, Sym]) AddSymbol(sym Sym) *Builder[S, Sym] {
+	b.checkFrozen()
 	b.symbols[sym] = struct{}{}
 	return b
 }
 
+// AddSymbols registers several input symbols.
+func (b *Builder[S, Sym]) AddSymbols(syms ...Sym) *Builder[S, Sym] {
+	for _, sym := range syms {
+		b.AddSymbol(sym)
+	}
+	return b
+}
+
+// AddSymbolString registers every rune of str for rune-symbol builders, or
+// every byte of str for byte-symbol builders. It panics for other symbol types.
+func (b *Builder[S, Sym]) AddSymbolString(str string) *Builder[S, Sym] {
+	switch any(*new(Sym)).(type) {
+	case rune:
+		for _, r := range str {
+			b.AddSymbol(any(r).(Sym))
+		}
+	case byte:
+		for i := 0; i < len(str); i++ {
+			b.AddSymbol(any(str[i]).(Sym))
+		}
+	default:
+		panic(fmt.Sprintf("AddSymbolString requires rune or byte symbols, have %T", *new(Sym)))
+	}
+	return b
+}
+
+// checkRegistered records, under WithStrictRegistration, a build error for
+// each of states and syms that has not been registered yet. The error names
+// the call described by format and args.
+func (b *Builder[S, Sym]) checkRegistered(states []S, syms []Sym, format string, args ...any) {
+	if !b.options.strictRegistration {
+		return
+	}
+	for i, s := range states {
+		if _, ok := b.states[s]; !ok && !slices.Contains(states[:i], s) {
+			b.pending = append(b.pending, newBuildError(KindUnregistered, "%s references unregistered state %v", fmt.Sprintf(format, args...), b.stateName(s)).withState(s))
+		}
+	}
+	for _, sym := range syms {
+		if _, ok := b.symbols[sym]; !ok {
+			b.pending = append(b.pending, newBuildError(KindUnregistered, "%s references unregistered symbol %v", fmt.Sprintf(format, args...), b.symbolName(sym)).withSymbol(sym))
+		}
+	}
+}
+
+// stateName and symbolName prepare a value for a message, applying the
+// namer options.
+func (b *Builder[S, Sym]) stateName(s S) any      { return nameWith(b.options.stateNamer, s) }
+func (b *Builder[S, Sym]) symbolName(sym Sym) any { return nameWith(b.options.symbolNamer, sym) }
+
+// duplicateError reports from --sym--> existing being redefined to to, named
+// with the builder's namers.
+func (b *Builder[S, Sym]) duplicateError(from S, sym Sym, existing, to S) *DuplicateTransitionError {
+	return &DuplicateTransitionError{From: from, Symbol: sym, Existing: existing, New: to,
+		stateNamer: b.options.stateNamer, symbolNamer: b.options.symbolNamer}
+}
+
+// registerImplicit registers states referenced before being declared.
+func (b *Builder[S, Sym]) registerImplicit(states ...S) {
+	for _, s := range states {
+		if _, ok := b.states[s]; !ok {
+			b.states[s] = struct{}{}
+			b.implicit[s] = struct{}{}
+		}
+	}
+}
+
 // On adds a transition: from --sym--> to. States and symbol are implicitly registered.
+// Redefining a transition overwrites it unless WithPreventOverwriteTransitions
+// (panic, or pending error under WithEagerValidation) or
+// WithCollectOverwriteErrors (Build error) is set, and
+// WithErrorOnOverwriteTransitions makes the overwrite fail Build; see also
+// OnChecked.
 func (b *Builder[S, Sym]) On(from S, sym Sym, to S) *Builder[S, Sym] {
-	b.states[from] = struct{}{}
-	b.states[to] = struct{}{}
+	b.checkFrozen()
+	b.checkRegistered([]S{from, to}, []Sym{sym}, "On(%v, %v, %v)", b.stateName(from), b.symbolName(sym), b.stateName(to))
+	b.registerImplicit(from, to)
 	b.symbols[sym] = struct{}{}
 	
 	key := TransitionKey[S, Sym]{From: from, Symbol: sym}
-	if _, exists := b.transitions[key]; exists && b.options.preventOverwriteTransitions {
-		panic(fmt.Sprintf("transition already defined for (%v,%v)", from, sym))
+	if existing, exists := b.transitions[key]; exists {
+		switch {
+		case b.options.collectOverwriteErrors, b.options.preventOverwriteTransitions && b.options.eagerValidation:
+			b.pending = append(b.pending, b.duplicateError(from, sym, existing, to))
+			return b
+		case b.options.preventOverwriteTransitions:
+			panic(fmt.Sprintf("transition already defined for (%v,%v)", b.stateName(from), b.symbolName(sym)))
+		case b.options.errorOnOverwriteTransitions:
+			b.pending = append(b.pending, b.duplicateError(from, sym, existing, to))
+			return b
+		case existing != to:
+			b.overwrites = append(b.overwrites, Warning{
+				Kind:    WarningOverwrittenTransition,
+				Message: fmt.Sprintf("overwritten transition from %v on %v: %v replaced by %v", b.stateName(from), b.symbolName(sym), b.stateName(existing), b.stateName(to)),
+			})
+		}
 	}
 	b.transitions[key] = to
 	return b
 }
 
+// OnChecked is like On but never overwrites: if from already has a transition
+// on sym it returns a DuplicateTransitionError and leaves the builder
+// unchanged, whatever the builder's options.
+func (b *Builder[S, Sym]) OnChecked(from S, sym Sym, to S) error {
+	if b.frozen {
+		return ErrBuilderFrozen
+	}
+	if existing, exists := b.transitions[TransitionKey[S, Sym]{From: from, Symbol: sym}]; exists {
+		return b.duplicateError(from, sym, existing, to)
+	}
+	b.On(from, sym, to)
+	return nil
+}
+
+// Row is a fluent view of a Builder bound to one source state, as returned by
+// Builder.From. Each On must be followed by exactly one To.
+type Row[S comparable, Sym comparable] struct {
+	b      *Builder[S, Sym]
+	from   S
+	sym    Sym
+	hasSym bool
+}
+
+// From registers state and returns a Row adding transitions out of it:
+// b.From("S1").On('0').To("S2").On('1').To("S0").
+func (b *Builder[S, Sym]) From(state S) *Row[S, Sym] {
+	b.checkFrozen()
+	b.checkRegistered([]S{state}, nil, "From(%v)", b.stateName(state))
+	b.registerImplicit(state)
+	return &Row[S, Sym]{b: b, from: state}
+}
+
+// On captures the symbol of the next transition. It panics if the previous
+// symbol has not been given a target with To.
+func (r *Row[S, Sym]) On(sym Sym) *Row[S, Sym] {
+	if r.hasSym {
+		panic(fmt.Sprintf("On(%v) after On(%v) from %v without To", sym, r.sym, r.from))
+	}
+	r.sym, r.hasSym = sym, true
+	return r
+}
+
+// To adds the transition on the captured symbol with Builder.On semantics. It
+// panics if no symbol was captured since the last To.
+func (r *Row[S, Sym]) To(state S) *Row[S, Sym] {
+	if !r.hasSym {
+		panic(fmt.Sprintf("To(%v) from %v without a preceding On", state, r.from))
+	}
+	r.hasSym = false
+	r.b.On(r.from, r.sym, state)
+	return r
+}
+
+// From starts a new row on the same builder.
+func (r *Row[S, Sym]) From(state S) *Row[S, Sym] {
+	if r.hasSym {
+		panic(fmt.Sprintf("From(%v) after On(%v) from %v without To", state, r.sym, r.from))
+	}
+	return r.b.From(state)
+}
+
+// Builder returns the builder the row adds to.
+func (r *Row[S, Sym]) Builder() *Builder[S, Sym] {
+	return r.b
+}
+
+// OnMany adds from --sym--> to for every symbol in syms, one On call per
+// symbol, so WithPreventOverwriteTransitions reports the offending symbol.
+func (b *Builder[S, Sym]) OnMany(from S, syms []Sym, to S) *Builder[S, Sym] {
+	for _, sym := range syms {
+		b.On(from, sym, to)
+	}
+	return b
+}
+
+// AddTransitions adds every transition of ts in order, registering states and
+// symbols as On does. Under WithPreventOverwriteTransitions the panic names the
+// index of the offending entry in ts.
+func (b *Builder[S, Sym]) AddTransitions(ts []Transition[S, Sym]) *Builder[S, Sym] {
+	for i, t := range ts {
+		key := TransitionKey[S, Sym]{From: t.From, Symbol: t.Symbol}
+		if _, exists := b.transitions[key]; exists && b.options.preventOverwriteTransitions && !b.options.collectOverwriteErrors && !b.options.eagerValidation {
+			panic(fmt.Sprintf("transition already defined for (%v,%v) at index %d", b.stateName(t.From), b.symbolName(t.Symbol), i))
+		}
+		b.On(t.From, t.Symbol, t.To)
+	}
+	return b
+}
+
+// OnString chains transitions spelling word from from to to, creating the
+// len(word)-1 intermediate states with mkState(i), where i is the number of
+// symbols consumed on reaching the state. An intermediate state that already
+// exists, or an empty word, makes Build fail instead of silently reusing
+// states.
+func (b *Builder[S, Sym]) OnString(from S, word []Sym, to S, mkState func(i int) S) *Builder[S, Sym] {
+	b.checkFrozen()
+	if len(word) == 0 {
+		b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "empty word from %v to %v", b.stateName(from), b.stateName(to)).withState(from).withTarget(to))
+		return b
+	}
+	cur := from
+	for i, sym := range word[:len(word)-1] {
+		next := mkState(i + 1)
+		if _, exists := b.states[next]; exists {
+			b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "intermediate state %v for word %v already exists", b.stateName(next), word).withState(next))
+		}
+		// Created here, so not an implicit registration.
+		b.states[next] = struct{}{}
+		b.On(cur, sym, next)
+		cur = next
+	}
+	return b.On(cur, word[len(word)-1], to)
+}
+
+// OnRuneString is OnString for string states and rune symbols. The
+// intermediate state after i runes is from followed by those runes.
+func OnRuneString(b *Builder[string, rune], from string, word string, to string) *Builder[string, rune] {
+	runes := []rune(word)
+	return b.OnString(from, runes, to, func(i int) string { return from + string(runes[:i]) })
+}
+
+// Integer is the constraint for the symbol types OnRange can enumerate, such
+// as byte and rune, and the state types NewState can allocate.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// OnRange adds from --sym--> to for every sym in the inclusive interval
+// [lo, hi], registering each symbol as On does. For runes every code point in
+// the interval is covered. An inverted interval (lo > hi) makes Build fail.
+func OnRange[S comparable, Sym Integer](b *Builder[S, Sym], from S, lo, hi Sym, to S) *Builder[S, Sym] {
+	b.checkFrozen()
+	if lo > hi {
+		b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "inverted range %v..%v from %v", b.symbolName(lo), b.symbolName(hi), b.stateName(from)).withState(from).withTarget(to))
+		return b
+	}
+	for sym := lo; ; sym++ {
+		b.On(from, sym, to)
+		if sym == hi {
+			// Checked before incrementing so hi may be the type's maximum.
+			break
+		}
+	}
+	return b
+}
+
+// NewState registers and returns a fresh state, counting up from 0 and
+// skipping states already registered, so generated states never collide with
+// explicit ones whatever the order of the calls.
+func NewState[S Integer, Sym comparable](b *Builder[S, Sym], accepting bool) S {
+	b.checkFrozen()
+	for {
+		s := b.nextState
+		b.nextState++
+		if _, ok := b.states[s]; !ok {
+			b.AddState(s, accepting)
+			return s
+		}
+	}
+}
+
+// NewStates allocates n fresh non-accepting states with NewState.
+func NewStates[S Integer, Sym comparable](b *Builder[S, Sym], n int) []S {
+	states := make([]S, n)
+	for i := range states {
+		states[i] = NewState(b, false)
+	}
+	return states
+}
+
+// OnAll adds from --sym--> to for every symbol registered so far, in the
+// package's deterministic order. Symbols registered after the call are not
+// covered retroactively.
+func (b *Builder[S, Sym]) OnAll(from S, to S) *Builder[S, Sym] {
+	return b.OnMany(from, sortedKeys(b.symbols), to)
+}
+
+// SelfLoop adds state --sym--> state for every sym, registering the state and
+// symbols as On does. Under WithPreventOverwriteTransitions it panics on the
+// first symbol that already has a transition from state.
+func (b *Builder[S, Sym]) SelfLoop(state S, syms ...Sym) *Builder[S, Sym] {
+	return b.OnMany(state, syms, state)
+}
+
+// SelfLoopAll adds a self-loop on state for every symbol registered so far.
+// Symbols registered after the call are not covered.
+func (b *Builder[S, Sym]) SelfLoopAll(state S) *Builder[S, Sym] {
+	return b.OnAll(state, state)
+}
+
+// OnElse makes to the target of every registered symbol that has no explicit
+// transition from from. The fill happens in Build, after all On calls and
+// regardless of their order, so symbols registered later are covered too and
+// the filled transitions are indistinguishable from explicit ones in the built
+// Machine. Fills never trigger WithPreventOverwriteTransitions. A later OnElse
+// for the same state replaces the earlier one.
+func (b *Builder[S, Sym]) OnElse(from S, to S) *Builder[S, Sym] {
+	b.checkFrozen()
+	b.checkRegistered([]S{from, to}, nil, "OnElse(%v, %v)", b.stateName(from), b.stateName(to))
+	b.registerImplicit(from, to)
+	if b.elseTargets == nil {
+		b.elseTargets = make(map[S]S)
+	}
+	b.elseTargets[from] = to
+	return b
+}
+
+// RemoveTransition deletes the transition from from on sym, if any.
+func (b *Builder[S, Sym]) RemoveTransition(from S, sym Sym) *Builder[S, Sym] {
+	b.checkFrozen()
+	delete(b.transitions, TransitionKey[S, Sym]{From: from, Symbol: sym})
+	return b
+}
+
+// RemoveSymbol drops sym from the alphabet together with every transition on it.
+func (b *Builder[S, Sym]) RemoveSymbol(sym Sym) *Builder[S, Sym] {
+	b.checkFrozen()
+	delete(b.symbols, sym)
+	for key := range b.transitions {
+		if key.Symbol == sym {
+			delete(b.transitions, key)
+		}
+	}
+	return b
+}
+
+// RemoveState deletes state together with its accepting flag, every transition
+// into or out of it and any OnElse default involving it. Removing the initial
+// state unsets it, so Build fails until SetInitial is called again.
+func (b *Builder[S, Sym]) RemoveState(state S) *Builder[S, Sym] {
+	b.checkFrozen()
+	delete(b.states, state)
+	delete(b.implicit, state)
+	delete(b.accepting, state)
+	for key, to := range b.transitions {
+		if key.From == state || to == state {
+			delete(b.transitions, key)
+		}
+	}
+	for from, to := range b.elseTargets {
+		if from == state || to == state {
+			delete(b.elseTargets, from)
+		}
+	}
+	if b.initialSet && b.initialState == state {
+		var zero S
+		b.initialSet = false
+		b.initialState = zero
+	}
+	return b
+}
+
+// ImportMachine copies the states, symbols, accepting states and transitions of
+// m into the builder. A transition already present with a different target is
+// resolved by the WithImportConflict policy; ConflictOverwrite makes Build fail
+// instead when the options forbid overwrites. The builder's initial state is
+// only replaced when WithImportInitial is given.
+func (b *Builder[S, Sym]) ImportMachine(m *Machine[S, Sym], opts ...ImportOption) *Builder[S, Sym] {
+	b.checkFrozen()
+	var o importOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for _, s := range m.stateList {
+		b.AddState(s, m.Accepting(s))
+	}
+	for sym := range m.symbols {
+		b.AddSymbol(sym)
+	}
+	for t := range m.Transitions() {
+		key := TransitionKey[S, Sym]{From: t.From, Symbol: t.Symbol}
+		existing, ok := b.transitions[key]
+		switch {
+		case ok && existing == t.To:
+			// Already present; not a conflict.
+		case !ok:
+			b.On(t.From, t.Symbol, t.To)
+		case o.conflict == ConflictOverwrite && !b.overwriteForbidden():
+			b.transitions[key] = t.To
+		case o.conflict == ConflictError || o.conflict == ConflictOverwrite:
+			b.pending = append(b.pending, newBuildError(KindConflict, "import conflict: transition from %v on %v leads to %v, imported machine has %v", b.stateName(t.From), b.symbolName(t.Symbol), b.stateName(existing), b.stateName(t.To)).withState(t.From).withSymbol(t.Symbol).withTarget(t.To))
+		}
+	}
+	if o.importInitial {
+		b.SetInitial(m.initialState)
+	}
+	return b
+}
+
+// Merge adds other's states, symbols, accepting states and transitions to b,
+// resolving transitions present in both with different targets by policy:
+// ConflictKeep keeps b's, ConflictOverwrite takes other's and ConflictError
+// reports every conflict, as does ConflictOverwrite when the options forbid
+// overwrites. OnElse defaults of other are copied for states that
+// have none in b. Both builders having different initial states is always an
+// error. When Merge returns an error, b is left unchanged.
+func (b *Builder[S, Sym]) Merge(other *Builder[S, Sym], policy ConflictPolicy) error {
+	if b.frozen {
+		return ErrBuilderFrozen
+	}
+	verr := &ValidationErrors{}
+	if b.initialSet && other.initialSet && b.initialState != other.initialState {
+		verr.Append(newBuildError(KindConflict, "merge conflict: initial state is %v, other builder has %v", b.stateName(b.initialState), b.stateName(other.initialState)).withState(b.initialState).withTarget(other.initialState))
+	}
+	if policy == ConflictError || policy == ConflictOverwrite && b.overwriteForbidden() {
+		for _, key := range sortedTransitionKeys(other.transitions) {
+			if ours, ok := b.transitions[key]; ok && ours != other.transitions[key] {
+				verr.Append(newBuildError(KindConflict, "merge conflict: transition from %v on %v leads to %v, other builder has %v", b.stateName(key.From), b.symbolName(key.Symbol), b.stateName(ours), b.stateName(other.transitions[key])).withState(key.From).withSymbol(key.Symbol).withTarget(other.transitions[key]))
+			}
+		}
+	}
+	if err := verr.AsError(); err != nil {
+		return err
+	}
+
+	for s := range other.states {
+		_, implicit := other.implicit[s]
+		if _, ok := b.states[s]; !ok && implicit {
+			b.implicit[s] = struct{}{}
+		} else if !implicit {
+			delete(b.implicit, s)
+		}
+		b.states[s] = struct{}{}
+	}
+	b.overwrites = append(b.overwrites, other.overwrites...)
+	for s := range other.accepting {
+		b.accepting[s] = struct{}{}
+	}
+	for sym := range other.symbols {
+		b.symbols[sym] = struct{}{}
+	}
+	for _, key := range sortedTransitionKeys(other.transitions) {
+		to := other.transitions[key]
+		ours, ok := b.transitions[key]
+		switch {
+		case ok && ours == to:
+			// Already present; not a conflict.
+		case !ok:
+			b.On(key.From, key.Symbol, to)
+		case policy == ConflictOverwrite:
+			b.transitions[key] = to
+		}
+	}
+	for from, to := range other.elseTargets {
+		if _, ok := b.elseTargets[from]; !ok {
+			b.OnElse(from, to)
+		}
+	}
+	if !b.initialSet && other.initialSet {
+		b.SetInitial(other.initialState)
+	}
+	b.pending = append(b.pending, other.pending...)
+	return nil
+}
+
+// overwriteForbidden reports whether the options forbid replacing the target
+// of a transition.
+func (b *Builder[S, Sym]) overwriteForbidden() bool {
+	return b.options.preventOverwriteTransitions || b.options.collectOverwriteErrors || b.options.errorOnOverwriteTransitions
+}
+
+// sortedTransitionKeys returns the keys of transitions ordered by source state
+// and then symbol.
+func sortedTransitionKeys[S comparable, Sym comparable](transitions map[TransitionKey[S, Sym]]S) []TransitionKey[S, Sym] {
+	keys := make([]TransitionKey[S, Sym], 0, len(transitions))
+	for key := range transitions {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b TransitionKey[S, Sym]) int {
+		if c := compareValues(a.From, b.From); c != 0 {
+			return c
+		}
+		return compareValues(a.Symbol, b.Symbol)
+	})
+	return keys
+}
+
 // Optional checks are extracted to helpers to keep Build concise.
 func (b *Builder[S, Sym]) checkRequireTotalTransitions(verr *ValidationErrors) {
 	if !b.options.requireTotalTransitions {
@@ -75,7 +705,7 @@ func (b *Builder[S, Sym]) checkRequireTotalTransitions(verr *ValidationErrors) {
 		for sym := range b.symbols {
 			key := TransitionKey[S, Sym]{From: s, Symbol: sym}
 			if _, ok := b.transitions[key]; !ok {
-				verr.Append(newBuildError("missing transition from %v on %v", s, sym))
+				verr.Append(newBuildError(KindMissingTransition, "missing transition from %v on %v", b.stateName(s), b.symbolName(sym)).withState(s).withSymbol(sym))
 			}
 		}
 	}
@@ -83,33 +713,21 @@ func (b *Builder[S, Sym]) checkRequireTotalTransitions(verr *ValidationErrors) {
 
 func (b *Builder[S, Sym]) checkRequireAtLeastOneAccepting(verr *ValidationErrors) {
 	if b.options.requireAtLeastOneAccepting && len(b.accepting) == 0 {
-		verr.Append(newBuildError("at least one accepting state required"))
+		verr.Append(newBuildError(KindNoAccepting, "at least one accepting state required"))
 	}
 }
 
-func (b *Builder[S, Sym]) checkReachability(verr *ValidationErrors) {
-	if !b.initialSet || !(b.options.errorOnUnreachableStates || b.options.errorWhenNoAcceptingReachable) {
+// checkReachability runs the forward and backward searches over v, a view of
+// the builder, whose adjacency indexes keep them linear in the transitions.
+func (b *Builder[S, Sym]) checkReachability(verr *ValidationErrors, v *Machine[S, Sym]) {
+	if !b.initialSet || !(b.options.errorOnUnreachableStates || b.options.errorWhenNoAcceptingReachable || b.options.requireAllStatesUseful || b.options.errorOnDeadStates) {
 		return
 	}
-	reached := make(map[S]struct{})
-	queue := []S{b.initialState}
-	reached[b.initialState] = struct{}{}
-
-	for i := 0; i < len(queue); i++ {
-		cur := queue[i]
-		for key, to := range b.transitions {
-			if key.From == cur {
-				if _, ok := reached[to]; !ok {
-					reached[to] = struct{}{}
-					queue = append(queue, to)
-				}
-			}
-		}
-	}
+	reached := v.reachable(b.initialState)
 	if b.options.errorOnUnreachableStates {
 		for s := range b.states {
-			if _, ok := reached[s]; !ok {
-				verr.Append(newBuildError("unreachable state %v", s))
+			if _, ok := reached[s]; !ok && !b.isSink(s) {
+				verr.Append(newBuildError(KindUnreachable, "unreachable state %v", b.stateName(s)).withState(s))
 			}
 		}
 	}
@@ -122,67 +740,281 @@ func (b *Builder[S, Sym]) checkReachability(verr *ValidationErrors) {
 			}
 		}
 		if !any {
-			verr.Append(newBuildError("no accepting state reachable from initial"))
+			verr.Append(newBuildError(KindNoAcceptingReachable, "no accepting state reachable from initial"))
+		}
+	}
+	if !b.options.requireAllStatesUseful && !b.options.errorOnDeadStates {
+		return
+	}
+	coreached := v.coreachable()
+	for _, s := range sortedKeys(b.states) {
+		if b.isSink(s) {
+			continue
+		}
+		_, isReached := reached[s]
+		_, isCoreached := coreached[s]
+		if b.options.requireAllStatesUseful {
+			// Covers dead states too, so the dead-state check is skipped.
+			switch {
+			case !isReached && !isCoreached:
+				verr.Append(newBuildError(KindUselessState, "useless state %v: unreachable and dead", b.stateName(s)).withState(s))
+			case !isReached:
+				verr.Append(newBuildError(KindUselessState, "useless state %v: unreachable", b.stateName(s)).withState(s))
+			case !isCoreached:
+				verr.Append(newBuildError(KindUselessState, "useless state %v: dead", b.stateName(s)).withState(s))
+			}
+		} else if isReached && !isCoreached {
+			verr.Append(newBuildError(KindDeadState, "dead state %v: cannot reach an accepting state", b.stateName(s)).withState(s))
+		}
+	}
+}
+
+// view exposes the builder's current definition as a Machine sharing the
+// builder's maps, so Machine analyses can back Build checks. It must only be
+// read, and only while the builder is not being mutated.
+func (b *Builder[S, Sym]) view() *Machine[S, Sym] {
+	return newMachine(b.initialState, b.states, b.accepting, b.symbols, b.transitions)
+}
+
+func (b *Builder[S, Sym]) checkDeadTransitions(verr *ValidationErrors, v *Machine[S, Sym]) {
+	if !b.initialSet || !b.options.errorOnDeadTransitions {
+		return
+	}
+	for _, t := range v.DeadTransitions() {
+		if b.isSink(t.To) {
+			continue
+		}
+		verr.Append(newBuildError(KindDeadTransition, "dead transition from %v on %v to %v", b.stateName(t.From), b.symbolName(t.Symbol), b.stateName(t.To)).withState(t.From).withSymbol(t.Symbol).withTarget(t.To))
+	}
+}
+
+func (b *Builder[S, Sym]) checkTerminalStates(verr *ValidationErrors) {
+	if !b.options.errorOnTerminalStates {
+		return
+	}
+	sources := make(map[S]struct{})
+	for key := range b.transitions {
+		sources[key.From] = struct{}{}
+	}
+	for _, s := range sortedKeys(b.states) {
+		if _, ok := sources[s]; ok {
+			continue
+		}
+		if _, ok := b.options.terminalStatesAllowed[s]; !ok {
+			verr.Append(newBuildError(KindTerminalState, "unexpected terminal state %v", b.stateName(s)).withState(s))
+		}
+	}
+}
+
+func (b *Builder[S, Sym]) checkInitialOutgoing(verr *ValidationErrors) {
+	if !b.initialSet || !b.options.requireInitialOutgoing {
+		return
+	}
+	for key := range b.transitions {
+		if key.From == b.initialState {
+			return
+		}
+	}
+	verr.Append(newBuildError(KindNoInitialOutgoing, "initial state %v has no outgoing transitions", b.stateName(b.initialState)).withState(b.initialState))
+}
+
+func (b *Builder[S, Sym]) checkUnusedSymbols(verr *ValidationErrors) {
+	if !b.options.errorOnUnusedSymbols {
+		return
+	}
+	used := make(map[Sym]struct{}, len(b.symbols))
+	for key, to := range b.transitions {
+		// Fills into the sink say nothing about the author's intent.
+		if !b.isSink(to) {
+			used[key.Symbol] = struct{}{}
+		}
+	}
+	for _, sym := range sortedKeys(b.symbols) {
+		if _, ok := used[sym]; !ok {
+			verr.Append(newBuildError(KindUnusedSymbol, "unused symbol %v", b.symbolName(sym)).withSymbol(sym))
+		}
+	}
+}
+
+// expand returns a copy of the builder holding the OnElse and
+// WithCompleteWithSink fills, leaving the builder's own definition untouched.
+func (b *Builder[S, Sym]) expand() *Builder[S, Sym] {
+	filled := *b
+	filled.expanded = true
+	filled.states = maps.Clone(b.states)
+	filled.transitions = maps.Clone(b.transitions)
+	fill := func(from, to S) {
+		for sym := range b.symbols {
+			key := TransitionKey[S, Sym]{From: from, Symbol: sym}
+			if _, ok := filled.transitions[key]; !ok {
+				filled.transitions[key] = to
+			}
 		}
 	}
+	for from, to := range b.elseTargets {
+		fill(from, to)
+	}
+	if sink, ok := b.options.completeSink.(S); ok && b.options.completeWithSink {
+		if _, ok := b.accepting[sink]; ok {
+			filled.pending = append(slices.Clone(b.pending), newBuildError(KindInvalidDefinition, "sink state %v is accepting", b.stateName(sink)).withState(sink))
+		}
+		filled.states[sink] = struct{}{}
+		for s := range filled.states {
+			fill(s, sink)
+		}
+	}
+	return &filled
+}
+
+// isSink reports whether state is the WithCompleteWithSink sink, which is
+// exempt from the reachability and usefulness checks.
+func (b *Builder[S, Sym]) isSink(state S) bool {
+	return b.options.completeWithSink && any(state) == b.options.completeSink
 }
 
-// Build validates and returns an immutable Machine.
+// Build validates and returns an immutable Machine. A successful Build freezes
+// the builder: further definition calls panic with ErrBuilderFrozen, and their
+// error-returning variants return it, until Reset. Use Clone to derive
+// variants, or WithMutableAfterBuild to keep the builder mutable.
 func (b *Builder[S, Sym]) Build() (*Machine[S, Sym], error) {
-	verr := &ValidationErrors{}
+	m, _, err := b.buildAndFreeze(false)
+	return m, err
+}
+
+// BuildWithReport is like Build but also returns a report of the findings that
+// do not make Build fail. The report is returned even when Build fails.
+func (b *Builder[S, Sym]) BuildWithReport() (*Machine[S, Sym], *BuildReport[S, Sym], error) {
+	return b.buildAndFreeze(true)
+}
+
+// buildAndFreeze builds the machine, with a report if withReport is set, and
+// freezes the builder on success unless WithMutableAfterBuild was given.
+func (b *Builder[S, Sym]) buildAndFreeze(withReport bool) (*Machine[S, Sym], *BuildReport[S, Sym], error) {
+	m, report, err := b.build(false, withReport)
+	if err == nil && !b.options.mutableAfterBuild {
+		b.frozen = true
+	}
+	return m, report, err
+}
+
+// BuildOwned is like Build but moves the builder's maps into the machine
+// instead of copying them, for builders discarded after building. On success
+// the builder is left empty, as after Reset, so it cannot alias the machine;
+// on failure it is unchanged.
+func (b *Builder[S, Sym]) BuildOwned() (*Machine[S, Sym], error) {
+	m, _, err := b.build(true, false)
+	if err == nil {
+		b.Reset()
+	}
+	return m, err
+}
+
+// build validates the definition and assembles the machine, from copies of
+// the builder's maps unless owned is set. The report is only computed when
+// withReport is set; otherwise it is nil.
+func (b *Builder[S, Sym]) build(owned, withReport bool) (*Machine[S, Sym], *BuildReport[S, Sym], error) {
+	if !b.expanded && (len(b.elseTargets) > 0 || b.options.completeWithSink) {
+		return b.expand().build(owned, withReport)
+	}
+	// One view for the report and the graph checks, so they share its
+	// predecessor index.
+	v := b.view()
+	var report *BuildReport[S, Sym]
+	var warnings []Warning
+	if withReport || b.options.warningsAsErrors {
+		warnings = b.warnings()
+	}
+	if withReport {
+		report = &BuildReport[S, Sym]{Warnings: warnings}
+		report.analyze(b, v)
+	}
+	verr := &ValidationErrors{limit: b.options.maxValidationErrors}
+	for _, err := range b.pending {
+		verr.Append(err)
+	}
 	if !b.initialSet {
-		verr.Append(newBuildError("initial state must be set"))
+		verr.Append(newBuildError(KindMissingInitial, "initial state must be set"))
 	}
 	if len(b.states) == 0 {
-		verr.Append(newBuildError("at least one state is required"))
+		verr.Append(newBuildError(KindNoStates, "at least one state is required"))
 	}
-	if len(b.symbols) == 0 {
-		verr.Append(newBuildError("at least one input symbol is required"))
+	if len(b.symbols) == 0 && !b.options.allowEmptyAlphabet {
+		verr.Append(newBuildError(KindNoSymbols, "at least one input symbol is required"))
 	}
 
 	// Ensure F ⊆ Q: every accepting state must be a registered state
 	for s := range b.accepting {
 		if _, ok := b.states[s]; !ok {
-			verr.Append(newBuildError("accepting state unknown %v", s))
+			verr.Append(newBuildError(KindUnknownAcceptingState, "accepting state unknown %v", b.stateName(s)).withState(s))
 		}
 	}
 
 	// Ensure all transitions reference known states/symbols.
 	for key, to := range b.transitions {
 		if _, ok := b.states[key.From]; !ok {
-			verr.Append(newBuildError("transition from unknown state %v", key.From))
+			verr.Append(newBuildError(KindUnknownTransitionState, "transition from unknown state %v", b.stateName(key.From)).withState(key.From).withSymbol(key.Symbol).withTarget(to))
 		}
 		if _, ok := b.symbols[key.Symbol]; !ok {
-			verr.Append(newBuildError("transition uses unknown symbol %v", key.Symbol))
+			verr.Append(newBuildError(KindUnknownSymbol, "transition uses unknown symbol %v", b.symbolName(key.Symbol)).withState(key.From).withSymbol(key.Symbol).withTarget(to))
 		}
 		if _, ok := b.states[to]; !ok {
-			verr.Append(newBuildError("transition to unknown state %v", to))
+			verr.Append(newBuildError(KindUnknownTransitionState, "transition to unknown state %v", b.stateName(to)).withState(key.From).withSymbol(key.Symbol).withTarget(to))
 		}
 	}
 
 	// Optional checks controlled by flags
 	b.checkRequireTotalTransitions(verr)
 	b.checkRequireAtLeastOneAccepting(verr)
-	b.checkReachability(verr)
+	b.checkReachability(verr, v)
+	b.checkDeadTransitions(verr, v)
+	b.checkTerminalStates(verr)
+	b.checkUnusedSymbols(verr)
+	b.checkInitialOutgoing(verr)
+	if b.options.warningsAsErrors {
+		for _, w := range warnings {
+			verr.Append(newBuildError(KindWarning, "%s", w.Message))
+		}
+	}
 
 	if err := verr.AsError(); err != nil {
-		return nil, err
+		return nil, report, err
 	}
 
-	// Copy into immutable machine.
-	acc := make(map[S]struct{}, len(b.accepting))
-	for s := range b.accepting {
-		acc[s] = struct{}{}
+	// Copy into immutable machine, or hand the maps over when owned.
+	states, acc, syms, trans := b.states, b.accepting, b.symbols, b.transitions
+	if !owned {
+		states, acc, syms, trans = maps.Clone(states), maps.Clone(acc), maps.Clone(syms), maps.Clone(trans)
 	}
-	trans := make(map[TransitionKey[S, Sym]]S, len(b.transitions))
-	for key, to := range b.transitions {
-		trans[key] = to
+	var m *Machine[S, Sym]
+	if less, ok := b.options.stateLess.(func(a, b S) bool); ok {
+		m = newMachineOrdered(b.initialState, b.orderStates(states, less), states, acc, syms, trans)
+	} else {
+		m = newMachine(b.initialState, states, acc, syms, trans)
 	}
-	return &Machine[S, Sym]{
-		initialState: b.initialState,
-		accepting:    acc,
-		transitions:  trans,
-	}, nil
+	m.stateNamer, m.symbolNamer = b.options.stateNamer, b.options.symbolNamer
+	m.buildOptions = b.options.info()
+	return m, report, nil
+}
+
+// orderStates lists states with the initial state first and the others
+// ordered by less.
+func (b *Builder[S, Sym]) orderStates(states map[S]struct{}, less func(a, b S) bool) []S {
+	list := make([]S, 0, len(states))
+	for s := range states {
+		if s != b.initialState {
+			list = append(list, s)
+		}
+	}
+	slices.SortFunc(list, func(x, y S) int {
+		switch {
+		case less(x, y):
+			return -1
+		case less(y, x):
+			return 1
+		}
+		return compareValues(x, y)
+	})
+	return append([]S{b.initialState}, list...)
 }
 
 
@@ -1,6 +1,11 @@
 package fsm
 
 import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -241,3 +246,1310 @@ func TestReachabilityComplexGraph(t *testing.T) {
 }
 
 
+
+func TestErrorOnDeadTransitions(t *testing.T) {
+	b := NewBuilder[string, rune](WithErrorOnDeadTransitions())
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'a', "B").On("A", 'x', "Trap")
+	if _, err := b.Build(); err == nil {
+		t.Fatalf("expected error for dead transition into Trap")
+	}
+
+	ok := NewBuilder[string, rune](WithErrorOnDeadTransitions())
+	ok.SetInitial("A")
+	ok.AddState("B", true)
+	ok.On("A", 'a', "B").On("B", 'a', "A")
+	if _, err := ok.Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+}
+
+func TestRequireAllStatesUseful(t *testing.T) {
+	b := NewBuilder[string, rune](WithRequireAllStatesUseful())
+	b.SetInitial("A")
+	b.AddState("B", true).AddState("Island", false)
+	b.On("A", 'a', "B").On("A", 'x', "Trap").On("U", 'a', "B")
+	_, err := b.Build()
+	if err == nil {
+		t.Fatalf("expected error for useless states")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"useless state Island: unreachable and dead",
+		"useless state Trap: dead",
+		"useless state U: unreachable",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("expected %q in error, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "state A") || strings.Contains(msg, "state B") {
+		t.Fatalf("useful states must not be reported, got:\n%s", msg)
+	}
+
+	if _, err := NewBuilder[string, rune](WithRequireAllStatesUseful()).
+		SetInitial("S0").AddState("S0", true).On("S0", 'x', "S0").Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+}
+
+func TestErrorOnTerminalStates(t *testing.T) {
+	build := func(opts ...Option) error {
+		b := NewBuilder[string, rune](opts...)
+		b.SetInitial("A")
+		b.AddState("Done", true).AddState("Isolated", false)
+		b.On("A", 'x', "Done").On("A", 'y', "Stuck")
+		_, err := b.Build()
+		return err
+	}
+	if err := build(WithErrorOnTerminalStates("Done", "Stuck", "Isolated")); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	err := build(WithErrorOnTerminalStates("Done"))
+	if err == nil {
+		t.Fatalf("expected build error for unexpected terminal states")
+	}
+	msg := err.Error()
+	for _, want := range []string{"unexpected terminal state Isolated", "unexpected terminal state Stuck"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("expected %q in %q", want, msg)
+		}
+	}
+	if strings.Contains(msg, "Done") || strings.Contains(msg, "state A") {
+		t.Fatalf("unexpected state reported in %q", msg)
+	}
+	if err := build(WithErrorOnTerminalStates[string]()); err == nil || !strings.Contains(err.Error(), "state Done") {
+		t.Fatalf("expected Done to be reported without exceptions, got %v", err)
+	}
+}
+
+func TestErrorOnTerminalStatesRejectsMistypedExceptions(t *testing.T) {
+	b := NewBuilder[int64, rune](WithErrorOnTerminalStates[int](1))
+	b.SetInitial(0).On(0, 'x', 1)
+	_, err := b.Build()
+	if !errors.Is(err, ErrInvalidDefinition) || !strings.Contains(err.Error(), "WithErrorOnTerminalStates: exception 1 is a int, not a int64") {
+		t.Fatalf("expected an exception type error, got %v", err)
+	}
+}
+
+func TestToBuilderRoundTrip(t *testing.T) {
+	m := buildMod3(t)
+	b := m.ToBuilder(WithRequireTotalTransitions())
+	rebuilt, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !Equal(m, rebuilt) || !slices.Equal(m.States(), rebuilt.States()) {
+		t.Fatalf("round trip changed the machine: %v", Diff(m, rebuilt))
+	}
+
+	// Extend the alphabet with a symbol that always leads to S2.
+	b = b.Clone()
+	b.On("S0", '2', "S2").On("S1", '2', "S2").On("S2", '2', "S2")
+	extended, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, _ := extended.Eval([]rune("1012")); got != "S2" {
+		t.Fatalf("expected S2 for 1012, got %v", got)
+	}
+	if got, _ := extended.Eval([]rune("11")); got != "S0" {
+		t.Fatalf("expected binary inputs to behave as before, got %v", got)
+	}
+	if _, err := m.Eval([]rune("2")); err == nil {
+		t.Fatalf("expected source machine to be unaffected by the builder")
+	}
+	if len(m.Symbols()) != 2 {
+		t.Fatalf("expected source alphabet to stay binary, got %q", m.Symbols())
+	}
+
+	// The total-transitions option given to ToBuilder applies to the new builder.
+	b = b.Clone()
+	b.AddSymbol('3')
+	if _, err := b.Build(); err == nil {
+		t.Fatalf("expected total-transitions error after adding a symbol without transitions")
+	}
+}
+
+func TestImportMachineDisjoint(t *testing.T) {
+	mod3 := buildMod3(t)
+	other := NewBuilder[string, rune]()
+	other.SetInitial("X")
+	other.AddState("Y", true)
+	other.On("X", 'a', "Y")
+	letters, err := other.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+
+	b := NewBuilder[string, rune]()
+	b.ImportMachine(mod3, WithImportInitial()).ImportMachine(letters)
+	b.On("S0", 'a', "X")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if m.InitialState() != "S0" {
+		t.Fatalf("expected initial state from first import, got %v", m.InitialState())
+	}
+	if ok, _ := m.EvalAccepting([]rune("11aa")); !ok {
+		t.Fatalf("expected 11aa to be accepted by the composed machine")
+	}
+	if got, want := m.States(), []string{"S0", "S1", "S2", "X", "Y"}; !slices.Equal(got, want) {
+		t.Fatalf("expected states %v, got %v", want, got)
+	}
+}
+
+func TestImportMachineOverlapping(t *testing.T) {
+	m := buildMod3(t)
+	base := func(opts ...Option) *Builder[string, rune] {
+		b := NewBuilder[string, rune](opts...)
+		b.SetInitial("S0")
+		b.On("S0", '1', "S2") // conflicts with mod3's S0 --1--> S1
+		b.On("S0", '0', "S0") // identical to mod3
+		return b
+	}
+
+	kept, err := base().ImportMachine(m, WithImportConflict(ConflictKeep)).Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if to, _ := kept.GetTransition("S0", '1'); to != "S2" {
+		t.Fatalf("expected kept target S2, got %v", to)
+	}
+
+	overwritten, err := base().ImportMachine(m).Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !Equal(overwritten, m) {
+		t.Fatalf("expected overwrite to reproduce mod3: %v", Diff(overwritten, m))
+	}
+
+	_, err = base().ImportMachine(m, WithImportConflict(ConflictError)).Build()
+	if err == nil || !strings.Contains(err.Error(), "import conflict: transition from S0 on 49") {
+		t.Fatalf("expected import conflict error, got %v", err)
+	}
+
+	// Identical transitions never conflict, even when overwrites are prevented.
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions())
+	b.SetInitial("S0").On("S0", '0', "S0")
+	if _, err := b.ImportMachine(m).Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+
+	// Overwrites forbidden by the options are reported instead of panicking.
+	for _, opt := range []Option{WithPreventOverwriteTransitions(), WithCollectOverwriteErrors(), WithErrorOnOverwriteTransitions()} {
+		_, err = base(opt).ImportMachine(m).Build()
+		if err == nil || !strings.Contains(err.Error(), "import conflict: transition from S0 on 49 leads to S2") {
+			t.Fatalf("expected import conflict error, got %v", err)
+		}
+	}
+}
+
+func TestVariadicRegistration(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddStates(true, "A", "B").AddStates(false, "B", "C") // accepting stays sticky
+	b.AddSymbols('x', 'y').AddSymbolString("xyzé")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, want := m.States(), []string{"A", "B", "C"}; !slices.Equal(got, want) {
+		t.Fatalf("expected states %v, got %v", want, got)
+	}
+	if !m.Accepting("A") || !m.Accepting("B") || m.Accepting("C") {
+		t.Fatalf("unexpected accepting set")
+	}
+	if got, want := m.Symbols(), []rune("xyzé"); !slices.Equal(got, want) {
+		t.Fatalf("expected symbols %q, got %q", want, got)
+	}
+
+	bytes := NewBuilder[string, byte]().AddSymbolString("01")
+	bytes.SetInitial("S")
+	bm, err := bytes.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := bm.Symbols(); !slices.Equal(got, []byte("01")) {
+		t.Fatalf("expected byte symbols 0 and 1, got %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for int symbols")
+		}
+	}()
+	NewBuilder[string, int]().AddSymbolString("01")
+}
+
+func TestOnManyAndOnAll(t *testing.T) {
+	b := NewBuilder[string, byte]()
+	b.SetInitial("Start")
+	b.AddState("Digits", true)
+	b.OnMany("Start", []byte("0123456789"), "Digits")
+	b.OnAll("Digits", "Digits")
+	b.AddSymbol('x') // registered after OnAll: not covered
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if ok, err := m.EvalAccepting([]byte("2024")); err != nil || !ok {
+		t.Fatalf("expected digits to be accepted, got %v %v", ok, err)
+	}
+	if m.OutDegree("Digits") != 10 || m.HasTransition("Digits", 'x') {
+		t.Fatalf("expected OnAll to cover exactly the ten digits, got %q", m.DefinedSymbols("Digits"))
+	}
+}
+
+func TestOnManyOverwritePanicNamesSymbol(t *testing.T) {
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions())
+	b.On("A", 'c', "B")
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected panic")
+		}
+		if msg := fmt.Sprint(r); !strings.Contains(msg, "(A,99)") {
+			t.Fatalf("expected offending symbol in panic, got %q", msg)
+		}
+	}()
+	b.OnMany("A", []rune("abc"), "C")
+}
+
+func TestOnElseFillsMissingTransitions(t *testing.T) {
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions(), WithRequireTotalTransitions())
+	b.SetInitial("Start")
+	b.AddState("Ident", true)
+	b.OnElse("Start", "Error").OnElse("Ident", "Error").OnElse("Error", "Error")
+	// Explicit transitions and symbols registered after OnElse still win or are covered.
+	b.OnMany("Start", []rune("ab"), "Ident")
+	b.OnMany("Ident", []rune("ab1"), "Ident")
+	b.AddSymbol('-')
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	for _, tc := range []struct {
+		from string
+		sym  rune
+		want string
+	}{
+		{"Start", 'a', "Ident"},
+		{"Start", '1', "Error"},
+		{"Start", '-', "Error"},
+		{"Ident", '1', "Ident"},
+		{"Ident", '-', "Error"},
+		{"Error", 'a', "Error"},
+	} {
+		if got, ok := m.GetTransition(tc.from, tc.sym); !ok || got != tc.want {
+			t.Errorf("δ(%s,%q) = %v,%v; want %s", tc.from, tc.sym, got, ok, tc.want)
+		}
+	}
+	if _, ok := b.TransitionFor("Start", '-'); ok {
+		t.Fatalf("expected fills to stay out of the builder's explicit transitions")
+	}
+	// An explicit transition added to a clone must not collide with an earlier fill.
+	b = b.Clone()
+	b.On("Start", '-', "Start")
+	if m, err = b.Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, _ := m.GetTransition("Start", '-'); got != "Start" {
+		t.Fatalf("expected explicit transition to win over the fill, got %v", got)
+	}
+}
+
+func TestOnElseKeepsExplicitOverwritePanic(t *testing.T) {
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions())
+	b.OnElse("A", "B").On("A", 'x', "A")
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for an explicit duplicate")
+		}
+	}()
+	b.On("A", 'x', "B")
+}
+
+func TestSelfLoopAndSelfLoopAll(t *testing.T) {
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions())
+	b.SetInitial("A")
+	b.SelfLoop("A", 'x', 'y').On("A", 'z', "B")
+	b.SelfLoopAll("B")
+	b.AddState("B", true)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.Symbols(); !slices.Equal(got, []rune("xyz")) {
+		t.Fatalf("expected SelfLoop to register its symbols, got %q", got)
+	}
+	for _, tc := range []struct {
+		state string
+		sym   rune
+	}{{"A", 'x'}, {"A", 'y'}, {"B", 'x'}, {"B", 'y'}, {"B", 'z'}} {
+		if to, ok := m.GetTransition(tc.state, tc.sym); !ok || to != tc.state {
+			t.Errorf("expected self-loop on %s over %q, got %v,%v", tc.state, tc.sym, to, ok)
+		}
+	}
+
+	defer func() {
+		if msg := fmt.Sprint(recover()); !strings.Contains(msg, "(A,120)") {
+			t.Fatalf("expected overwrite panic naming the symbol, got %q", msg)
+		}
+	}()
+	b.Clone().SelfLoopAll("A")
+}
+
+func TestAddTransitionsRoundTripsEdgeList(t *testing.T) {
+	src, err := NewBuilder[string, rune]().
+		SetInitial("A").
+		AddState("C", true).
+		On("A", 'x', "B").On("B", 'y', "C").On("C", 'x', "A").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	m, err := NewBuilder[string, rune]().
+		SetInitial("A").
+		AddState("C", true).
+		AddTransitions(src.EdgeList()).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !Equal(src, m) {
+		t.Fatalf("expected identical machines, diff: %v", Diff(src, m))
+	}
+}
+
+func TestAddTransitionsOverwritePanicNamesIndex(t *testing.T) {
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions())
+	defer func() {
+		msg := fmt.Sprint(recover())
+		if !strings.Contains(msg, "at index 2") {
+			t.Fatalf("expected panic naming index 2, got %q", msg)
+		}
+	}()
+	b.AddTransitions([]Transition[string, rune]{
+		{From: "A", Symbol: 'x', To: "B"},
+		{From: "B", Symbol: 'x', To: "A"},
+		{From: "A", Symbol: 'x', To: "A"},
+	})
+}
+
+func TestOnStringChainsWord(t *testing.T) {
+	b := NewBuilder[int, byte]()
+	b.SetInitial(0)
+	b.AddState(100, true)
+	b.OnString(0, []byte("for"), 100, func(i int) int { return 10 + i })
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.States(); !slices.Equal(got, []int{0, 11, 12, 100}) {
+		t.Fatalf("expected two intermediate states, got %v", got)
+	}
+	if ok, err := m.EvalAccepting([]byte("for")); err != nil || !ok {
+		t.Fatalf("expected keyword to be accepted, got %v %v", ok, err)
+	}
+}
+
+func TestOnStringCollisionIsBuildError(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("")
+	b.AddState("kw", true)
+	b.AddState("i", false)
+	OnRuneString(b, "", "if", "kw")
+	_, err := b.Build()
+	if err == nil || !strings.Contains(err.Error(), "intermediate state i for word") {
+		t.Fatalf("expected collision error, got %v", err)
+	}
+}
+
+func TestOnRuneStringNamesStates(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("s")
+	b.AddState("kw", true)
+	OnRuneString(b, "s", "héllo", "kw")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.States(); !slices.Equal(got, []string{"s", "kw", "sh", "shé", "shél", "shéll"}) {
+		t.Fatalf("unexpected states %v", got)
+	}
+}
+
+func TestOnRangeIdentifierRecognizer(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("start")
+	b.AddState("ident", true)
+	OnRange(b, "start", 'a', 'z', "ident")
+	OnRange(b, "start", 'A', 'Z', "ident")
+	OnRange(b, "ident", 'a', 'z', "ident")
+	OnRange(b, "ident", 'A', 'Z', "ident")
+	OnRange(b, "ident", '0', '9', "ident")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if n := len(m.Symbols()); n != 62 {
+		t.Fatalf("expected 62 symbols, got %d", n)
+	}
+	for in, want := range map[string]bool{"x": true, "Var42": true, "a1b2": true, "": false, "9lives": false} {
+		ok, _ := m.EvalAccepting([]rune(in))
+		if ok != want {
+			t.Errorf("%q: want %v, got %v", in, want, ok)
+		}
+	}
+}
+
+func TestOnRangeCoversTypeMaximum(t *testing.T) {
+	b := NewBuilder[int, byte]()
+	b.SetInitial(0)
+	OnRange(b, 0, 250, 255, 0)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.Symbols(); !slices.Equal(got, []byte{250, 251, 252, 253, 254, 255}) {
+		t.Fatalf("unexpected alphabet %v", got)
+	}
+}
+
+func TestOnRangeInvertedIsBuildError(t *testing.T) {
+	b := NewBuilder[int, rune]()
+	b.SetInitial(0).AddSymbol('a')
+	OnRange(b, 0, 'z', 'a', 1)
+	_, err := b.Build()
+	if err == nil || !strings.Contains(err.Error(), "inverted range") {
+		t.Fatalf("expected inverted range error, got %v", err)
+	}
+}
+
+func TestNewStateSkipsExplicitStates(t *testing.T) {
+	b := NewBuilder[int, rune]()
+	b.AddState(1, false).AddState(3, true)
+	auto := NewStates(b, 3)
+	if !slices.Equal(auto, []int{0, 2, 4}) {
+		t.Fatalf("expected auto states to skip explicit ones, got %v", auto)
+	}
+	b.AddState(5, false)
+	if s := NewState(b, true); s != 6 || !b.IsAccepting(6) {
+		t.Fatalf("expected accepting state 6 after explicit 5, got %v", s)
+	}
+	if b.IsAccepting(4) || !b.IsAccepting(3) {
+		t.Fatalf("expected NewStates to add non-accepting states only")
+	}
+	if got := b.States(); !slices.Equal(got, []int{0, 1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("expected seven distinct states, got %v", got)
+	}
+	if s := NewState(b.Clone(), false); s != 7 {
+		t.Fatalf("expected the clone to continue the count, got %v", s)
+	}
+}
+
+func TestRemoveTransitionAndSymbol(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A").AddState("B", true)
+	b.On("A", 'x', "B").On("A", 'y', "B").On("B", 'y', "A").On("B", 'z', "B")
+	b.RemoveTransition("A", 'x').RemoveTransition("A", 'q').RemoveSymbol('y').RemoveSymbol('q')
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.Symbols(); !slices.Equal(got, []rune("xz")) {
+		t.Fatalf("expected alphabet xz, got %q", got)
+	}
+	if got := m.EdgeList(); len(got) != 1 || got[0] != (Transition[string, rune]{From: "B", Symbol: 'z', To: "B"}) {
+		t.Fatalf("expected only B --z--> B to remain, got %v", got)
+	}
+}
+
+func TestRemoveStateCascades(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A").AddState("B", true).AddState("C", true)
+	b.On("A", 'x', "B").On("B", 'x', "C").On("C", 'x', "A").On("C", 'y', "C")
+	b.OnElse("A", "B")
+	b.RemoveState("B").RemoveState("missing")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.States(); !slices.Equal(got, []string{"A", "C"}) {
+		t.Fatalf("expected states A and C, got %v", got)
+	}
+	if got := m.EdgeList(); len(got) != 2 {
+		t.Fatalf("expected the transitions touching B and its OnElse default to be gone, got %v", got)
+	}
+	if m.Accepting("B") {
+		t.Fatalf("expected removed state to lose its accepting flag")
+	}
+}
+
+func TestRemoveInitialStateUnsetsInitial(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A").AddState("B", true).On("A", 'x', "B")
+	b.RemoveState("A")
+	_, err := b.Build()
+	if err == nil || !strings.Contains(err.Error(), "initial state must be set") {
+		t.Fatalf("expected missing initial state error, got %v", err)
+	}
+	if _, err := b.SetInitial("B").Build(); err != nil {
+		t.Fatalf("unexpected build error after resetting initial: %v", err)
+	}
+}
+
+func TestSetAcceptingClearsStickyFlag(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A").AddState("B", true).On("A", 'x', "B").On("B", 'x', "A")
+	b.AddState("B", false)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !m.Accepting("B") {
+		t.Fatalf("expected AddState(B, false) to keep B accepting")
+	}
+
+	b = b.Clone()
+	b.SetAccepting("B", false).SetAccepting("A", true).SetAccepting("C", false)
+	m, err = b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if m.Accepting("B") || !m.Accepting("A") {
+		t.Fatalf("expected only A to be accepting")
+	}
+	if got := m.States(); !slices.Contains(got, "C") {
+		t.Fatalf("expected SetAccepting to register C, got %v", got)
+	}
+}
+
+func TestCloneDivergesIndependently(t *testing.T) {
+	base := NewBuilder[string, rune](WithPreventOverwriteTransitions())
+	base.SetInitial("A").AddState("B", true)
+	base.On("A", 'x', "B").On("B", 'x', "A")
+
+	variant := base.Clone()
+	variant.SetAccepting("A", true).On("B", 'y', "B")
+	base.On("A", 'y', "A").SetAccepting("B", false).SetAccepting("A", true)
+
+	mb, err := base.Build()
+	if err != nil {
+		t.Fatalf("unexpected base build error: %v", err)
+	}
+	mv, err := variant.Build()
+	if err != nil {
+		t.Fatalf("unexpected variant build error: %v", err)
+	}
+	d := DiffMachines(mb, mv)
+	if len(d.BecameAccepting) != 1 || d.BecameAccepting[0] != "B" || len(d.StoppedAccepting) != 0 {
+		t.Fatalf("expected only B to differ in acceptance, got %+v", d)
+	}
+	want := []Transition[string, rune]{{From: "A", Symbol: 'y', To: "A"}}
+	if !slices.Equal(d.RemovedTransitions, want) {
+		t.Fatalf("expected removed %v, got %v", want, d.RemovedTransitions)
+	}
+	want = []Transition[string, rune]{{From: "B", Symbol: 'y', To: "B"}}
+	if !slices.Equal(d.AddedTransitions, want) {
+		t.Fatalf("expected added %v, got %v", want, d.AddedTransitions)
+	}
+
+	// Options are copied too.
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected clone to keep WithPreventOverwriteTransitions")
+		}
+	}()
+	variant.On("A", 'x', "A")
+}
+
+// mergeParts returns two builders sharing state B, whose transitions on x
+// disagree.
+func mergeParts(opts ...Option) (ours, theirs *Builder[string, rune]) {
+	ours = NewBuilder[string, rune](opts...)
+	ours.SetInitial("A").AddState("B", true)
+	ours.On("A", 'x', "B").On("B", 'x', "A")
+	theirs = NewBuilder[string, rune]()
+	theirs.AddState("C", true)
+	theirs.On("B", 'x', "B").On("B", 'y', "C").On("C", 'y', "A")
+	return ours, theirs
+}
+
+func TestMergePolicies(t *testing.T) {
+	for _, tc := range []struct {
+		policy ConflictPolicy
+		want   string
+	}{
+		{ConflictKeep, "A"},
+		{ConflictOverwrite, "B"},
+	} {
+		ours, theirs := mergeParts()
+		if err := ours.Merge(theirs, tc.policy); err != nil {
+			t.Fatalf("policy %v: unexpected merge error: %v", tc.policy, err)
+		}
+		m, err := ours.Build()
+		if err != nil {
+			t.Fatalf("policy %v: unexpected build error: %v", tc.policy, err)
+		}
+		if got, _ := m.GetTransition("B", 'x'); got != tc.want {
+			t.Errorf("policy %v: expected B --x--> %s, got %s", tc.policy, tc.want, got)
+		}
+		if !m.Accepting("C") || !m.HasTransition("C", 'y') || len(m.Symbols()) != 2 {
+			t.Errorf("policy %v: expected their states, symbols and transitions to be merged", tc.policy)
+		}
+	}
+}
+
+func TestMergeConflictErrorListsTargets(t *testing.T) {
+	ours, theirs := mergeParts()
+	before := ours.Clone()
+	err := ours.Merge(theirs, ConflictError)
+	var verr *ValidationErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "transition from B on 120 leads to A, other builder has B") {
+		t.Fatalf("expected conflict with both targets, got %q", msg)
+	}
+	if to, _ := ours.TransitionFor("B", 'x'); to != "A" || !slices.Equal(ours.States(), before.States()) || !slices.Equal(ours.Symbols(), before.Symbols()) {
+		t.Fatalf("expected a failed merge to leave the builder unchanged")
+	}
+}
+
+func TestMergeOverwriteForbiddenByOptions(t *testing.T) {
+	for _, opt := range []Option{WithPreventOverwriteTransitions(), WithCollectOverwriteErrors(), WithErrorOnOverwriteTransitions()} {
+		ours, theirs := mergeParts(opt)
+		before := ours.Clone()
+		err := ours.Merge(theirs, ConflictOverwrite)
+		if err == nil || !strings.Contains(err.Error(), "transition from B on 120 leads to A, other builder has B") {
+			t.Fatalf("expected merge conflict, got %v", err)
+		}
+		if to, _ := ours.TransitionFor("B", 'x'); to != "A" || !slices.Equal(ours.States(), before.States()) || len(ours.PendingErrors()) != 0 {
+			t.Fatalf("expected a failed merge to leave the builder unchanged")
+		}
+	}
+}
+
+func TestMergeInitialConflict(t *testing.T) {
+	ours, theirs := mergeParts()
+	theirs.SetInitial("C")
+	for _, policy := range []ConflictPolicy{ConflictOverwrite, ConflictKeep, ConflictError} {
+		err := ours.Merge(theirs, policy)
+		if err == nil || !strings.Contains(err.Error(), "initial state is A, other builder has C") {
+			t.Fatalf("policy %v: expected initial conflict, got %v", policy, err)
+		}
+	}
+	// A matching initial state or an unset one is fine.
+	theirs.SetInitial("A")
+	if err := ours.Merge(theirs, ConflictKeep); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBuilderIntrospection(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	if _, ok := b.Initial(); ok {
+		t.Fatalf("expected no initial state on a fresh builder")
+	}
+	b.SetInitial("B").AddState("A", true).On("B", 'y', "C").AddSymbol('x')
+	if got := b.States(); !slices.Equal(got, []string{"A", "B", "C"}) {
+		t.Fatalf("unexpected states %v", got)
+	}
+	if got := b.Symbols(); !slices.Equal(got, []rune("xy")) {
+		t.Fatalf("unexpected symbols %q", got)
+	}
+	if s, ok := b.Initial(); !ok || s != "B" {
+		t.Fatalf("expected initial B, got %v,%v", s, ok)
+	}
+	if !b.IsAccepting("A") || b.IsAccepting("B") {
+		t.Fatalf("unexpected accepting flags")
+	}
+	if to, ok := b.TransitionFor("B", 'y'); !ok || to != "C" {
+		t.Fatalf("expected B --y--> C, got %v,%v", to, ok)
+	}
+	if _, ok := b.TransitionFor("B", 'x'); ok {
+		t.Fatalf("did not expect a transition on x")
+	}
+	// Returned slices are copies.
+	b.States()[0] = "Z"
+	if got := b.States(); got[0] != "A" {
+		t.Fatalf("expected States to return a copy, got %v", got)
+	}
+}
+
+func TestFromRowChaining(t *testing.T) {
+	b := NewBuilder[string, byte](WithPreventOverwriteTransitions())
+	b.AddStates(true, "S0", "S1", "S2").SetInitial("S0")
+	b.From("S0").On('0').To("S0").On('1').To("S1").
+		From("S1").On('0').To("S2").On('1').To("S0").
+		From("S2").On('0').To("S1").On('1').To("S2")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, err := m.Eval([]byte("1110")); err != nil || got != "S2" {
+		t.Fatalf("expected S2, got %v %v", got, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected Row.To to keep WithPreventOverwriteTransitions")
+		}
+	}()
+	b.From("S0").On('0').To("S1")
+}
+
+func TestFromRowMisusePanics(t *testing.T) {
+	for name, misuse := range map[string]func(r *Row[string, rune]){
+		"To twice":      func(r *Row[string, rune]) { r.On('x').To("B").To("C") },
+		"To without On": func(r *Row[string, rune]) { r.To("B") },
+		"On twice":      func(r *Row[string, rune]) { r.On('x').On('y') },
+		"From after On": func(r *Row[string, rune]) { r.On('x').From("B") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				msg := fmt.Sprint(recover())
+				if !strings.Contains(msg, "from A") {
+					t.Fatalf("expected a panic naming the row, got %q", msg)
+				}
+			}()
+			misuse(NewBuilder[string, rune]().From("A"))
+		})
+	}
+}
+
+func TestOnCheckedReturnsDuplicateError(t *testing.T) {
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions())
+	b.SetInitial("A").AddState("B", true)
+	if err := b.OnChecked("A", 'x', "B"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := b.OnChecked("A", 'x', "A")
+	var dup *DuplicateTransitionError
+	if !errors.As(err, &dup) || dup.From != "A" || dup.Symbol != 'x' || dup.Existing != "B" || dup.New != "A" {
+		t.Fatalf("expected DuplicateTransitionError, got %#v", err)
+	}
+	if to, _ := b.TransitionFor("A", 'x'); to != "B" {
+		t.Fatalf("expected the first target to be kept, got %v", to)
+	}
+	if _, err := b.Build(); err != nil {
+		t.Fatalf("a rejected OnChecked must not fail Build: %v", err)
+	}
+}
+
+func TestCollectOverwriteErrors(t *testing.T) {
+	b := NewBuilder[string, rune](WithCollectOverwriteErrors(), WithPreventOverwriteTransitions())
+	b.SetInitial("A").AddState("B", true)
+	b.On("A", 'x', "B").On("A", 'x', "A").On("B", 'x', "A")
+	b.AddTransitions([]Transition[string, rune]{{From: "B", Symbol: 'x', To: "B"}})
+	_, err := b.Build()
+	var verr *ValidationErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{"(A,120): leads to B, redefined to A", "(B,120): leads to A, redefined to B"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in %q", want, msg)
+		}
+	}
+	if to, _ := b.TransitionFor("A", 'x'); to != "B" {
+		t.Fatalf("expected the first target to be kept, got %v", to)
+	}
+}
+
+func TestErrorOnOverwriteTransitionsReportsAll(t *testing.T) {
+	b := NewBuilder[string, rune](WithErrorOnOverwriteTransitions())
+	b.SetInitial("A").AddState("B", true)
+	b.On("A", 'x', "B").On("A", 'x', "A").On("A", 'x', "B")
+	b.On("B", 'y', "A").On("B", 'y', "B")
+	if to, _ := b.TransitionFor("A", 'x'); to != "B" {
+		t.Fatalf("expected the first target to be kept, got %v", to)
+	}
+	if to, _ := b.TransitionFor("B", 'y'); to != "A" {
+		t.Fatalf("expected the first target to be kept, got %v", to)
+	}
+	_, err := b.Build()
+	if err == nil {
+		t.Fatalf("expected overwrite errors")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"(A,120): leads to B, redefined to A",
+		"(A,120): leads to B, redefined to B",
+		"(B,121): leads to A, redefined to B",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in %q", want, msg)
+		}
+	}
+}
+
+func TestErrorOnOverwriteConflictsWithPrevent(t *testing.T) {
+	defer func() {
+		if msg := fmt.Sprint(recover()); !strings.Contains(msg, "mutually exclusive") {
+			t.Fatalf("expected NewBuilder to reject both options, got %q", msg)
+		}
+	}()
+	NewBuilder[string, rune](WithPreventOverwriteTransitions(), WithErrorOnOverwriteTransitions())
+}
+
+// defineWithTypo declares a two-state machine but misspells the initial state
+// in one transition.
+func defineWithTypo(b *Builder[string, rune]) {
+	b.AddState("Start", false).AddState("B", true).AddSymbol('x')
+	b.SetInitial("Start")
+	b.On("Start", 'x', "B").On("Strt", 'x', "B")
+}
+
+func TestStrictRegistrationCatchesTypos(t *testing.T) {
+	b := NewBuilder[string, rune](WithStrictRegistration())
+	defineWithTypo(b)
+	b.On("B", 'y', "B")
+	_, err := b.Build()
+	if err == nil {
+		t.Fatalf("expected strict registration errors")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"On(Strt, 120, B) references unregistered state Strt",
+		"On(B, 121, B) references unregistered symbol 121",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in %q", want, msg)
+		}
+	}
+	if strings.Count(msg, "unregistered") != 2 {
+		t.Errorf("expected exactly two errors, got %q", msg)
+	}
+}
+
+func TestEagerValidationReportsAtDefinitionTime(t *testing.T) {
+	b := NewBuilder[string, rune](WithEagerValidation(), WithPreventOverwriteTransitions(), WithStrictRegistration())
+	b.AddStates(true, "A", "B").AddSymbols('x').SetInitial("A")
+	b.On("A", 'x', "B")
+	if errs := b.PendingErrors(); len(errs) != 0 {
+		t.Fatalf("expected no pending errors, got %v", errs)
+	}
+	b.On("A", 'x', "A") // no panic
+	errs := b.PendingErrors()
+	var dup *DuplicateTransitionError
+	if len(errs) != 1 || !errors.As(errs[0], &dup) || dup.Existing != "B" {
+		t.Fatalf("expected one duplicate transition error, got %v", errs)
+	}
+	if to, _ := b.TransitionFor("A", 'x'); to != "B" {
+		t.Fatalf("expected the first target to be kept, got %v", to)
+	}
+	b.SetInitial("Strt")
+	if errs = b.PendingErrors(); len(errs) != 2 || !errors.Is(errs[1], ErrUnregistered) {
+		t.Fatalf("expected an unregistered state error, got %v", errs)
+	}
+	errs[0] = nil // PendingErrors returns a copy
+	_, err := b.Build()
+	var verr *ValidationErrors
+	if !errors.As(err, &verr) || len(verr.Errors()) != 2 {
+		t.Fatalf("expected Build to report both pending errors, got %v", err)
+	}
+}
+
+func TestEagerValidationAddTransitionsDoesNotPanic(t *testing.T) {
+	b := NewBuilder[string, rune](WithPreventOverwriteTransitions(), WithEagerValidation())
+	b.SetInitial("a").AddState("b", true)
+	b.AddTransitions([]Transition[string, rune]{{From: "a", Symbol: 'x', To: "b"}, {From: "a", Symbol: 'x', To: "a"}})
+	errs := b.PendingErrors()
+	var dup *DuplicateTransitionError
+	if len(errs) != 1 || !errors.As(errs[0], &dup) || dup.Existing != "b" || dup.New != "a" {
+		t.Fatalf("expected one pending duplicate transition error, got %v", errs)
+	}
+}
+
+func TestImplicitRegistrationIsDefault(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	defineWithTypo(b)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !slices.Contains(m.States(), "Strt") {
+		t.Fatalf("expected the typo to be registered as a state")
+	}
+}
+
+func TestCompleteWithSinkTotalizes(t *testing.T) {
+	b := NewBuilder[string, rune](WithCompleteWithSink("sink"), WithRequireTotalTransitions(), WithRequireAllStatesUseful(), WithErrorOnDeadTransitions())
+	b.SetInitial("A").AddState("B", true).AddSymbols('x', 'y')
+	b.On("A", 'x', "B").On("B", 'y', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if m.Accepting("sink") || !m.IsSink("sink") {
+		t.Fatalf("expected a non-accepting self-looping sink")
+	}
+	for _, tc := range []struct {
+		from string
+		sym  rune
+		want string
+	}{{"A", 'x', "B"}, {"A", 'y', "sink"}, {"B", 'x', "sink"}, {"B", 'y', "A"}} {
+		if got, _ := m.GetTransition(tc.from, tc.sym); got != tc.want {
+			t.Errorf("δ(%s,%q) = %s; want %s", tc.from, tc.sym, got, tc.want)
+		}
+	}
+	if _, ok := b.TransitionFor("A", 'y'); ok || slices.Contains(b.States(), "sink") {
+		t.Fatalf("expected the builder's own definition to stay untouched")
+	}
+}
+
+func TestCompleteWithSinkUnreachableIsNotFlagged(t *testing.T) {
+	b := NewBuilder[string, rune](WithCompleteWithSink("sink"), WithErrorOnUnreachableStates())
+	b.SetInitial("A").AddState("A", true).SelfLoop("A", 'x', 'y')
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !slices.Contains(m.States(), "sink") || m.InDegree("sink") != 2 {
+		t.Fatalf("expected an unreachable sink with only its self-loops, got %v", m.EdgeList())
+	}
+	// Other unreachable states are still reported.
+	if _, err := b.Clone().AddState("orphan", false).Build(); err == nil || !strings.Contains(err.Error(), "unreachable state orphan") {
+		t.Fatalf("expected unreachable orphan error, got %v", err)
+	}
+}
+
+func TestCompleteWithSinkRejectsAcceptingSink(t *testing.T) {
+	b := NewBuilder[string, rune](WithCompleteWithSink("A"))
+	b.SetInitial("A").AddState("A", true).AddSymbol('x')
+	if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), "sink state A is accepting") {
+		t.Fatalf("expected accepting sink error, got %v", err)
+	}
+}
+
+func TestCompleteWithSinkRejectsMistypedSink(t *testing.T) {
+	b := NewBuilder[int, rune](WithCompleteWithSink("sink"))
+	b.SetInitial(0).AddState(0, true).AddSymbol('x')
+	_, err := b.Build()
+	if !errors.Is(err, ErrInvalidDefinition) || !strings.Contains(err.Error(), "WithCompleteWithSink: sink sink is a string, not a int") {
+		t.Fatalf("expected a sink type error, got %v", err)
+	}
+	// An untyped constant defaults to int, not the builder's int64.
+	wide := NewBuilder[int64, rune](WithCompleteWithSink(99))
+	wide.SetInitial(0).AddState(0, true).AddSymbol('x')
+	if _, err := wide.Build(); !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatalf("expected a sink type error, got %v", err)
+	}
+}
+
+func TestErrorOnUnusedSymbols(t *testing.T) {
+	define := func(opts ...Option) *Builder[string, rune] {
+		b := NewBuilder[string, rune](opts...)
+		b.SetInitial("A").AddState("B", true).AddSymbols('u', 'v', 'w')
+		b.On("A", 'u', "B") // used
+		b.On("B", 'i', "B") // implicitly registered and used
+		return b
+	}
+	if _, err := define().Build(); err != nil {
+		t.Fatalf("unexpected build error without the option: %v", err)
+	}
+	_, err := define(WithErrorOnUnusedSymbols()).Build()
+	if err == nil {
+		t.Fatalf("expected unused symbol errors")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "unused symbol 118") || !strings.Contains(msg, "unused symbol 119") || strings.Count(msg, "unused symbol") != 2 {
+		t.Fatalf("expected v and w to be reported, got %q", msg)
+	}
+
+	// Symbols covered by OnElse or SelfLoopAll count as used.
+	b := define(WithErrorOnUnusedSymbols())
+	b.OnElse("A", "B").SelfLoopAll("B")
+	if _, err := b.Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	// Sink fills do not.
+	b = define(WithErrorOnUnusedSymbols(), WithCompleteWithSink("sink"))
+	if _, err := b.Build(); err == nil || strings.Count(err.Error(), "unused symbol") != 2 {
+		t.Fatalf("expected sink fills not to count as uses, got %v", err)
+	}
+}
+
+func TestErrorOnDeadStatesFlagsReachableTrap(t *testing.T) {
+	define := func(opts ...Option) *Builder[string, rune] {
+		b := NewBuilder[string, rune](opts...)
+		b.SetInitial("A").AddState("B", true).AddState("orphan", false)
+		b.On("A", 'x', "B").On("A", 'y', "trap").SelfLoop("trap", 'x', 'y')
+		b.On("orphan", 'x', "trap")
+		return b
+	}
+	if _, err := define().Build(); err != nil {
+		t.Fatalf("unexpected build error without the option: %v", err)
+	}
+	_, err := define(WithErrorOnDeadStates(), WithErrorOnUnreachableStates()).Build()
+	if err == nil {
+		t.Fatalf("expected a dead state error")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "dead state trap: cannot reach an accepting state") {
+		t.Fatalf("expected trap to be reported, got %q", msg)
+	}
+	// orphan is dead too, but only reported as unreachable.
+	if strings.Count(msg, "orphan") != 1 || !strings.Contains(msg, "unreachable state orphan") {
+		t.Fatalf("expected orphan to be reported once as unreachable, got %q", msg)
+	}
+}
+
+func TestMaxValidationErrorsCapsAcrossChecks(t *testing.T) {
+	define := func(opts ...Option) *Builder[int, int] {
+		b := NewBuilder[int, int](append(opts, WithRequireTotalTransitions(), WithErrorOnUnreachableStates())...)
+		b.SetInitial(0).AddStates(false, 1, 2)
+		for sym := range 100 {
+			b.AddSymbol(sym)
+		}
+		OnRange(b, 0, 5, 1, 0) // inverted: a pending error
+		return b
+	}
+	_, err := define().Build()
+	var verr *ValidationErrors
+	if !errors.As(err, &verr) || len(verr.errors) != 303 {
+		t.Fatalf("expected 303 errors without a cap, got %v", err)
+	}
+
+	_, err = define(WithMaxValidationErrors(10)).Build()
+	if !errors.As(err, &verr) || len(verr.errors) != 11 {
+		t.Fatalf("expected 10 errors plus a summary, got %v", err)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "inverted range") || !strings.HasSuffix(msg, "… and 293 more errors (truncated)") {
+		t.Fatalf("unexpected capped message %q", msg)
+	}
+	// Repeated AsError calls add the summary once.
+	if verr.AsError(); len(verr.errors) != 11 {
+		t.Fatalf("expected the summary to be appended once, got %d errors", len(verr.errors))
+	}
+}
+
+func TestAllowEmptyAlphabet(t *testing.T) {
+	if _, err := NewBuilder[string, rune]().SetInitial("q0").Build(); err == nil {
+		t.Fatalf("expected an empty alphabet to be rejected by default")
+	}
+	for _, accepting := range []bool{true, false} {
+		m, err := NewBuilder[string, rune](WithAllowEmptyAlphabet()).AddState("q0", accepting).SetInitial("q0").Build()
+		if err != nil {
+			t.Fatalf("accepting=%v: unexpected build error: %v", accepting, err)
+		}
+		if s, err := m.Eval(nil); err != nil || s != "q0" {
+			t.Fatalf("accepting=%v: expected Eval(nil) to stay in q0, got %v %v", accepting, s, err)
+		}
+		if ok, _ := m.EvalAccepting(nil); ok != accepting {
+			t.Fatalf("accepting=%v: empty input acceptance is %v", accepting, ok)
+		}
+		var terr *TransitionError[string, rune]
+		if _, err := m.Eval([]rune("a")); !errors.As(err, &terr) {
+			t.Fatalf("accepting=%v: expected TransitionError, got %v", accepting, err)
+		}
+	}
+}
+
+func TestRequireInitialOutgoing(t *testing.T) {
+	define := func(opts ...Option) *Builder[string, rune] {
+		b := NewBuilder[string, rune](opts...)
+		b.SetInitial("start").AddState("start", true).On("other", 'x', "start")
+		return b
+	}
+	if _, err := define().Build(); err != nil {
+		t.Fatalf("unexpected build error without the option: %v", err)
+	}
+	_, err := define(WithRequireInitialOutgoing()).Build()
+	if err == nil || !strings.Contains(err.Error(), "initial state start has no outgoing transitions") {
+		t.Fatalf("expected initial out-degree error, got %v", err)
+	}
+	// Expansions count.
+	if _, err := define(WithRequireInitialOutgoing()).OnElse("start", "other").Build(); err != nil {
+		t.Fatalf("expected an OnElse default to count, got %v", err)
+	}
+	if _, err := define(WithRequireInitialOutgoing(), WithCompleteWithSink("sink")).Build(); err != nil {
+		t.Fatalf("expected sink completion to count, got %v", err)
+	}
+}
+
+func TestBuildFreezesBuilder(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A").AddState("A", true).SelfLoop("A", 'x')
+	// A failed Build leaves the builder mutable.
+	if _, err := NewBuilder[string, rune]().Build(); err == nil {
+		t.Fatalf("expected build error for an empty builder")
+	}
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	for name, mutate := range map[string]func(){
+		"AddState":   func() { b.AddState("B", false) },
+		"AddSymbol":  func() { b.AddSymbol('y') },
+		"On":         func() { b.On("A", 'y', "A") },
+		"SetInitial": func() { b.SetInitial("B") },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrBuilderFrozen {
+					t.Errorf("%s: expected ErrBuilderFrozen panic, got %v", name, r)
+				}
+			}()
+			mutate()
+		}()
+	}
+	if err := b.OnChecked("A", 'y', "A"); !errors.Is(err, ErrBuilderFrozen) {
+		t.Fatalf("expected OnChecked to return ErrBuilderFrozen, got %v", err)
+	}
+	if err := b.Merge(NewBuilder[string, rune](), ConflictKeep); !errors.Is(err, ErrBuilderFrozen) {
+		t.Fatalf("expected Merge to return ErrBuilderFrozen, got %v", err)
+	}
+	// Rebuilding a frozen builder is allowed and yields the same machine.
+	again, err := b.Build()
+	if err != nil || !Equal(m, again) {
+		t.Fatalf("expected an identical rebuild, got %v", err)
+	}
+}
+
+func TestCloneOfBuiltBuilderIsMutable(t *testing.T) {
+	base := NewBuilder[string, rune]()
+	base.SetInitial("A").AddState("A", true).SelfLoop("A", 'x')
+	if _, err := base.Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	variant := base.Clone().SelfLoop("A", 'y')
+	m, err := variant.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.Symbols(); !slices.Equal(got, []rune("xy")) {
+		t.Fatalf("expected the clone's extra symbol, got %q", got)
+	}
+	if got := base.Symbols(); !slices.Equal(got, []rune("x")) {
+		t.Fatalf("expected the frozen original to be unchanged, got %q", got)
+	}
+}
+
+func TestResetClearsAndUnfreezes(t *testing.T) {
+	b := NewBuilder[string, rune](WithRequireTotalTransitions())
+	b.SetInitial("A").AddState("A", true).SelfLoop("A", 'x').OnElse("A", "A")
+	if _, err := b.Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	b.Reset()
+	if len(b.States()) != 0 || len(b.Symbols()) != 0 {
+		t.Fatalf("expected an empty definition after Reset, got %v %q", b.States(), b.Symbols())
+	}
+	if _, ok := b.Initial(); ok {
+		t.Fatalf("expected Reset to unset the initial state")
+	}
+	b.SetInitial("P").AddState("Q", true).AddSymbols('a', 'b').On("P", 'a', "Q")
+	_, err := b.Build()
+	if err == nil || !strings.Contains(err.Error(), "missing transition") {
+		t.Fatalf("expected Reset to keep the total-transitions option, got %v", err)
+	}
+}
+
+func TestBuildOwnedLeavesBuilderEmpty(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.AddState("A", false).AddState("B", true).AddSymbols('x')
+	// A failed build keeps the definition.
+	if _, err := b.BuildOwned(); err == nil {
+		t.Fatalf("expected missing initial state error")
+	}
+	if len(b.States()) != 2 {
+		t.Fatalf("expected a failed BuildOwned to leave the builder intact, got %v", b.States())
+	}
+	b.SetInitial("A").On("A", 'x', "B").On("B", 'x', "A")
+	m, err := b.BuildOwned()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if len(b.States()) != 0 || len(b.Symbols()) != 0 {
+		t.Fatalf("expected an empty builder, got %v %q", b.States(), b.Symbols())
+	}
+	// Reusing the builder must not reach the machine's maps.
+	b.SetInitial("A").AddState("B", false).On("A", 'x', "A").AddSymbol('y')
+	if to, _ := m.GetTransition("A", 'x'); to != "B" || !m.Accepting("B") || len(m.Symbols()) != 1 {
+		t.Fatalf("expected the machine to be unaffected by the reused builder")
+	}
+}
+
+func TestMutableAfterBuild(t *testing.T) {
+	b := NewBuilder[string, rune](WithMutableAfterBuild())
+	b.SetInitial("A").AddState("A", true).SelfLoop("A", 'x')
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	b.SelfLoop("A", 'y')
+	if err := b.OnChecked("A", 'z', "A"); err != nil {
+		t.Fatalf("unexpected OnChecked error: %v", err)
+	}
+	second, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if len(first.Symbols()) != 1 || len(second.Symbols()) != 3 {
+		t.Fatalf("expected independent machines, got %q and %q", first.Symbols(), second.Symbols())
+	}
+}
+
+// BenchmarkBuildReachabilityChecks builds a large random machine with every
+// graph check enabled; their cost must stay linear in the transitions.
+func BenchmarkBuildReachabilityChecks(b *testing.B) {
+	const states, symbols = 20000, 8
+	rng := rand.New(rand.NewPCG(1, 2))
+	bld := NewBuilder[int, int](
+		WithMutableAfterBuild(),
+		WithErrorOnUnreachableStates(),
+		WithErrorWhenNoAcceptingReachable(),
+		WithRequireAllStatesUseful(),
+		WithErrorOnDeadTransitions(),
+	)
+	bld.SetInitial(0)
+	for s := range states {
+		bld.AddState(s, s%100 == 0)
+		// A spanning chain keeps every state reachable and useful.
+		bld.On(s, 0, (s+1)%states)
+		for sym := 1; sym < symbols; sym++ {
+			bld.On(s, sym, rng.IntN(states))
+		}
+	}
+	b.ResetTimer()
+	for range b.N {
+		if _, err := bld.Build(); err != nil {
+			b.Fatalf("unexpected build error: %v", err)
+		}
+	}
+}
+
+func benchmarkBuild(b *testing.B, build func(*Builder[int, int]) (*Machine[int, int], error)) {
+	b.ReportAllocs()
+	for range b.N {
+		b.StopTimer()
+		bld := NewBuilder[int, int]()
+		bld.SetInitial(0)
+		for s := range 10000 {
+			for sym := range 20 {
+				bld.On(s, sym, (s*7+sym)%10000)
+			}
+		}
+		b.StartTimer()
+		if _, err := build(bld); err != nil {
+			b.Fatalf("unexpected build error: %v", err)
+		}
+	}
+}
+
+func BenchmarkBuildCopy(b *testing.B) {
+	benchmarkBuild(b, (*Builder[int, int]).Build)
+}
+
+func BenchmarkBuildOwned(b *testing.B) {
+	benchmarkBuild(b, (*Builder[int, int]).BuildOwned)
+}
@@ -0,0 +1,84 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DivergenceReport describes where two machines first disagree on an input.
+type DivergenceReport[S comparable, Sym comparable] struct {
+	// Diverged is false when both machines agree on every prefix.
+	Diverged bool
+	// Index is the length of the first prefix on which the machines disagree.
+	Index int
+	// Symbol is the last symbol consumed before disagreeing; unset when Index is 0.
+	Symbol     Sym
+	StateA     S
+	StateB     S
+	AcceptingA bool
+	AcceptingB bool
+	// ErrA and ErrB hold the step error of a machine that failed at Index.
+	ErrA error
+	ErrB error
+}
+
+// String renders the report on a single line.
+func (r DivergenceReport[S, Sym]) String() string {
+	if !r.Diverged {
+		return "no divergence"
+	}
+	side := func(state S, accepting bool, err error) string {
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return fmt.Sprintf("state %v (accepting=%v)", state, accepting)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diverged at index %d", r.Index)
+	if r.Index > 0 {
+		fmt.Fprintf(&sb, " after symbol %v", r.Symbol)
+	}
+	fmt.Fprintf(&sb, ": a %s, b %s", side(r.StateA, r.AcceptingA, r.ErrA), side(r.StateB, r.AcceptingB, r.ErrB))
+	return sb.String()
+}
+
+// CompareRun steps a and b in lockstep over input and reports the first prefix
+// on which they disagree, either in acceptance or because exactly one of them
+// has no transition. If both fail on the same symbol there is nothing left to
+// compare and the error of a is returned.
+func CompareRun[S comparable, Sym comparable](a, b *Machine[S, Sym], input []Sym) (DivergenceReport[S, Sym], error) {
+	ra, rb := a.Start(), b.Start()
+	report := func(i int) DivergenceReport[S, Sym] {
+		r := DivergenceReport[S, Sym]{
+			Index:      i,
+			StateA:     ra.State(),
+			StateB:     rb.State(),
+			AcceptingA: a.Accepting(ra.State()),
+			AcceptingB: b.Accepting(rb.State()),
+		}
+		if i > 0 {
+			r.Symbol = input[i-1]
+		}
+		r.Diverged = r.AcceptingA != r.AcceptingB
+		return r
+	}
+	if r := report(0); r.Diverged {
+		return r, nil
+	}
+	for i, sym := range input {
+		errA, errB := ra.Step(sym), rb.Step(sym)
+		switch {
+		case errA != nil && errB != nil:
+			return DivergenceReport[S, Sym]{}, errA
+		case errA != nil || errB != nil:
+			r := report(i + 1)
+			r.Diverged = true
+			r.ErrA, r.ErrB = errA, errB
+			return r, nil
+		}
+		if r := report(i + 1); r.Diverged {
+			return r, nil
+		}
+	}
+	return DivergenceReport[S, Sym]{}, nil
+}
@@ -0,0 +1,66 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareRunCorruptedMod3(t *testing.T) {
+	good := buildMod3(t)
+	b := NewBuilder[string, rune]()
+	b.AddState("S0", true).AddState("S1", false).AddState("S2", false)
+	b.SetInitial("S0")
+	b.On("S0", '0', "S0").On("S0", '1', "S1")
+	b.On("S1", '0', "S2").On("S1", '1', "S0")
+	b.On("S2", '0', "S1").On("S2", '1', "S0") // corrupted: should stay in S2
+	bad, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+
+	// "0010" reaches S2 in both; the next 1 leads to S2 vs S0.
+	report, err := CompareRun(good, bad, []rune("0010111"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Diverged || report.Index != 5 || report.Symbol != '1' {
+		t.Fatalf("expected divergence at index 5 on '1', got %+v", report)
+	}
+	if report.StateA != "S2" || report.StateB != "S0" || report.AcceptingA || !report.AcceptingB {
+		t.Fatalf("unexpected states in report %+v", report)
+	}
+	if s := report.String(); !strings.Contains(s, "index 5") || !strings.Contains(s, "state S0 (accepting=true)") {
+		t.Fatalf("unexpected report string %q", s)
+	}
+
+	same, err := CompareRun(good, good, []rune("0010111"))
+	if err != nil || same.Diverged || same.String() != "no divergence" {
+		t.Fatalf("expected no divergence, got %v, err: %v", same, err)
+	}
+}
+
+func TestCompareRunTransitionErrors(t *testing.T) {
+	full := buildMod3(t)
+	b := NewBuilder[string, rune]()
+	b.AddState("S0", true)
+	b.SetInitial("S0")
+	b.On("S0", '0', "S0")
+	partial, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	report, err := CompareRun(full, partial, []rune("0011"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Diverged || report.Index != 3 || report.ErrA != nil || report.ErrB == nil {
+		t.Fatalf("expected b to fail at index 3, got %+v", report)
+	}
+	if !strings.Contains(report.String(), "b error: no transition") {
+		t.Fatalf("unexpected report string %q", report.String())
+	}
+
+	if _, err := CompareRun(partial, partial, []rune("1")); err == nil {
+		t.Fatalf("expected error when both machines fail on the same symbol")
+	}
+}
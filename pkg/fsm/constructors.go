@@ -0,0 +1,184 @@
+package fsm
+
+import "fmt"
+
+// Constructors for common machine shapes.
+
+// checkAlphabet reports the first pattern symbol missing from the alphabet.
+func checkAlphabet[Sym comparable](pattern []Sym, alphabet []Sym) error {
+	known := make(map[Sym]struct{}, len(alphabet))
+	for _, sym := range alphabet {
+		known[sym] = struct{}{}
+	}
+	for i, sym := range pattern {
+		if _, ok := known[sym]; !ok {
+			return fmt.Errorf("pattern symbol %v at index %d is not in the alphabet", sym, i)
+		}
+	}
+	return nil
+}
+
+// kmpRows computes the Knuth-Morris-Pratt transition rows for pattern over
+// alphabet. Row j (j < len(pattern)) is the row of the state that has matched
+// the first j pattern symbols; the extra last row is the one the full-match
+// state would have if matching continued past it.
+func kmpRows[Sym comparable](pattern []Sym, alphabet []Sym) []map[Sym]int {
+	n := len(pattern)
+	rows := make([]map[Sym]int, n+1)
+	rows[0] = make(map[Sym]int, len(alphabet))
+	for _, sym := range alphabet {
+		rows[0][sym] = 0
+	}
+	// x tracks the state reached on the pattern with its first symbol dropped
+	// (the failure state).
+	x := 0
+	for j := 0; j <= n; j++ {
+		if j > 0 {
+			rows[j] = make(map[Sym]int, len(alphabet))
+			for _, sym := range alphabet {
+				rows[j][sym] = rows[x][sym]
+			}
+		}
+		if j < n {
+			if j > 0 {
+				x = rows[x][pattern[j]]
+			}
+			rows[j][pattern[j]] = j + 1
+		}
+	}
+	return rows
+}
+
+// NewFromTable builds a machine from a transition table mapping each source
+// state to its row of symbol -> target entries. Every row key, target and
+// accepting state is registered, so a state with an empty row is kept. The
+// table is fed through a Builder configured with opts in deterministic order,
+// so all build options and validations apply.
+func NewFromTable[S comparable, Sym comparable](initial S, accepting []S, table map[S]map[Sym]S, opts ...Option) (*Machine[S, Sym], error) {
+	b := NewBuilder[S, Sym](opts...)
+	b.SetInitial(initial)
+	b.AddStates(true, accepting...)
+	for _, from := range sortedKeys(table) {
+		row := table[from]
+		b.AddStates(false, from)
+		for _, sym := range sortedKeys(row) {
+			b.On(from, sym, row[sym])
+		}
+	}
+	return b.BuildOwned()
+}
+
+// minimalTotal minimizes m and, if that left it partial, routes the missing
+// transitions to a fresh sink state.
+func minimalTotal[S comparable, Sym comparable](m *Machine[S, Sym]) (*Machine[int, Sym], error) {
+	mm := m.Minimize()
+	n := len(mm.States())
+	if len(mm.transitions) == n*len(mm.symbols) {
+		return mm, nil
+	}
+	return mm.Complete(n)
+}
+
+// BuildSubstringMachine returns the Knuth-Morris-Pratt automaton accepting
+// every input over alphabet that contains pattern as a contiguous substring.
+// State i means the longest pattern prefix matched so far has length i; the
+// final state len(pattern) is accepting and absorbing. The machine is total
+// over alphabet. An empty pattern yields a machine accepting everything.
+func BuildSubstringMachine[Sym comparable](pattern []Sym, alphabet []Sym) (*Machine[int, Sym], error) {
+	if err := checkAlphabet(pattern, alphabet); err != nil {
+		return nil, err
+	}
+	n := len(pattern)
+	rows := kmpRows(pattern, alphabet)
+	b := NewBuilder[int, Sym](WithRequireTotalTransitions())
+	b.SetInitial(0)
+	b.AddState(n, true)
+	b.SelfLoop(n, alphabet...)
+	for j, row := range rows[:n] {
+		for sym, to := range row {
+			b.On(j, sym, to)
+		}
+	}
+	return b.BuildOwned()
+}
+
+// BuildPrefixMachine returns the minimal total machine over alphabet accepting
+// exactly the inputs that start with prefix. An empty prefix accepts everything.
+func BuildPrefixMachine[Sym comparable](prefix []Sym, alphabet []Sym) (*Machine[int, Sym], error) {
+	if err := checkAlphabet(prefix, alphabet); err != nil {
+		return nil, err
+	}
+	n := len(prefix)
+	b := NewBuilder[int, Sym]()
+	b.SetInitial(0)
+	b.AddState(n, true)
+	b.SelfLoop(n, alphabet...)
+	for i, sym := range prefix {
+		b.On(i, sym, i+1)
+	}
+	m, err := b.BuildOwned()
+	if err != nil {
+		return nil, err
+	}
+	return minimalTotal(m)
+}
+
+// BuildSuffixMachine returns the minimal total machine over alphabet accepting
+// exactly the inputs that end with suffix. It is the substring automaton whose
+// full-match state keeps matching instead of absorbing. An empty suffix
+// accepts everything.
+func BuildSuffixMachine[Sym comparable](suffix []Sym, alphabet []Sym) (*Machine[int, Sym], error) {
+	if err := checkAlphabet(suffix, alphabet); err != nil {
+		return nil, err
+	}
+	b := NewBuilder[int, Sym]()
+	b.SetInitial(0)
+	b.AddState(len(suffix), true)
+	for _, sym := range alphabet {
+		b.AddSymbol(sym)
+	}
+	for j, row := range kmpRows(suffix, alphabet) {
+		for sym, to := range row {
+			b.On(j, sym, to)
+		}
+	}
+	m, err := b.BuildOwned()
+	if err != nil {
+		return nil, err
+	}
+	return minimalTotal(m)
+}
+
+// FromWords returns a trie-shaped machine accepting exactly the given words.
+// State 0 is the root and every other state is a distinct word prefix, so the
+// machine is partial: any deviation from the listed words has no transition.
+// Duplicate words are tolerated and an empty word makes the initial state
+// accepting. FromWordsMinimal also merges shared suffixes.
+func FromWords[Sym comparable](words [][]Sym) (*Machine[int, Sym], error) {
+	b := NewBuilder[int, Sym]()
+	root := NewState(b, false)
+	b.SetInitial(root)
+	for _, w := range words {
+		cur := root
+		for _, sym := range w {
+			to, ok := b.TransitionFor(cur, sym)
+			if !ok {
+				to = NewState(b, false)
+				b.On(cur, sym, to)
+			}
+			cur = to
+		}
+		b.AddState(cur, true)
+	}
+	return b.BuildOwned()
+}
+
+// FromWordsMinimal is like FromWords but minimizes the trie, merging shared
+// suffixes, so the result is the minimal partial machine for the words.
+func FromWordsMinimal[Sym comparable](words [][]Sym) (*Machine[int, Sym], error) {
+	m, err := FromWords(words)
+	if err != nil {
+		return nil, err
+	}
+	return m.Minimize(), nil
+}
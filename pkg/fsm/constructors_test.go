@@ -0,0 +1,177 @@
+package fsm
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildSubstringMachineMatchesContains(t *testing.T) {
+	alphabet := []byte("abc")
+	rng := rand.New(rand.NewSource(3))
+	for _, pattern := range []string{"a", "abab", "aab", "abcab", "ccc"} {
+		m, err := BuildSubstringMachine([]byte(pattern), alphabet)
+		if err != nil {
+			t.Fatalf("pattern %q: unexpected error: %v", pattern, err)
+		}
+		if universal, err := m.IsUniversal(); err != nil || universal {
+			t.Fatalf("pattern %q: expected total, non-universal machine, got %v, err: %v", pattern, universal, err)
+		}
+		for i := 0; i < 400; i++ {
+			in := make([]byte, rng.Intn(16))
+			for j := range in {
+				in[j] = alphabet[rng.Intn(len(alphabet))]
+			}
+			got, err := m.EvalAccepting(in)
+			if err != nil {
+				t.Fatalf("unexpected eval error: %v", err)
+			}
+			if want := bytes.Contains(in, []byte(pattern)); got != want {
+				t.Fatalf("pattern %q on %q: want %v, got %v", pattern, in, want, got)
+			}
+		}
+	}
+}
+
+func TestBuildSubstringMachineEdgeCases(t *testing.T) {
+	m, err := BuildSubstringMachine(nil, []byte("ab"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if universal, err := m.IsUniversal(); err != nil || !universal {
+		t.Fatalf("empty pattern should accept everything, got %v, err: %v", universal, err)
+	}
+	if _, err := BuildSubstringMachine([]byte("abz"), []byte("ab")); err == nil {
+		t.Fatalf("expected error for pattern symbol outside the alphabet")
+	}
+}
+
+func TestBuildPrefixAndSuffixMachines(t *testing.T) {
+	alphabet := []rune("ab")
+	for _, affix := range []string{"", "a", "ab", "aab", "abab"} {
+		pm, err := BuildPrefixMachine([]rune(affix), alphabet)
+		if err != nil {
+			t.Fatalf("prefix %q: unexpected error: %v", affix, err)
+		}
+		sm, err := BuildSuffixMachine([]rune(affix), alphabet)
+		if err != nil {
+			t.Fatalf("suffix %q: unexpected error: %v", affix, err)
+		}
+		for _, m := range []*Machine[int, rune]{pm, sm} {
+			if _, err := m.IsUniversal(); err != nil {
+				t.Fatalf("affix %q: expected total machine: %v", affix, err)
+			}
+			if n, min := len(m.States()), len(m.Minimize().States()); n > min+1 {
+				t.Fatalf("affix %q: %d states, minimal partial machine has %d", affix, n, min)
+			}
+		}
+		for _, w := range allStrings(alphabet, 7) {
+			s := string(w)
+			if got, want := acceptsOrRejects(pm, w), strings.HasPrefix(s, affix); got != want {
+				t.Fatalf("prefix %q on %q: want %v, got %v", affix, s, want, got)
+			}
+			if got, want := acceptsOrRejects(sm, w), strings.HasSuffix(s, affix); got != want {
+				t.Fatalf("suffix %q on %q: want %v, got %v", affix, s, want, got)
+			}
+		}
+	}
+}
+
+func TestBuildPrefixAndSuffixUnknownSymbol(t *testing.T) {
+	if _, err := BuildPrefixMachine([]rune("ax"), []rune("ab")); err == nil {
+		t.Fatalf("expected error for prefix symbol outside the alphabet")
+	}
+	if _, err := BuildSuffixMachine([]rune("xa"), []rune("ab")); err == nil {
+		t.Fatalf("expected error for suffix symbol outside the alphabet")
+	}
+}
+
+func TestFromWordsAcceptsExactlyTheWords(t *testing.T) {
+	words := []string{"to", "tea", "ted", "ten", "in", "inn", "tea"}
+	input := make([][]rune, len(words))
+	for i, w := range words {
+		input[i] = []rune(w)
+	}
+	m, err := FromWords(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := make(map[string]bool)
+	for _, w := range words {
+		want[w] = true
+	}
+	alphabet := []rune("toeadni")
+	for _, w := range allStrings(alphabet, 3) {
+		if got := acceptsOrRejects(m, w); got != want[string(w)] {
+			t.Fatalf("%q: want %v, got %v", string(w), want[string(w)], got)
+		}
+	}
+	if n := len(m.States()); n != 10 {
+		t.Fatalf("expected 10 trie nodes, got %d", n)
+	}
+	// "tea", "ted" and "ten" share their final states once minimized.
+	mm := m.Minimize()
+	if n := len(mm.States()); n >= 10 {
+		t.Fatalf("expected minimization to merge suffixes, got %d states", n)
+	}
+	assertSameLanguage(t, m, mm, alphabet, 3)
+}
+
+func TestFromWordsMinimal(t *testing.T) {
+	words := [][]rune{[]rune("to"), []rune("tea"), []rune("ted"), []rune("ten"), []rune("in"), []rune("inn")}
+	trie, err := FromWords(words)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := FromWordsMinimal(words)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The root, "t", "i", "te" and "in", plus one final state shared by every
+	// word ending.
+	if n := len(m.States()); n != 6 {
+		t.Fatalf("expected 6 states after minimization, got %d: %v", n, m.EdgeList())
+	}
+	assertSameLanguage(t, trie, m, []rune("toeadni"), 4)
+}
+
+func TestFromWordsEmptyWord(t *testing.T) {
+	m, err := FromWords([][]byte{{}, []byte("a")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.Accepting(m.InitialState()) {
+		t.Fatalf("empty word should make the initial state accepting")
+	}
+}
+
+func TestNewFromTable(t *testing.T) {
+	m, err := NewFromTable("even", []string{"even"}, map[string]map[rune]string{
+		"even": {'a': "odd", 'b': "even"},
+		"odd":  {'a': "even", 'b': "odd"},
+		"idle": {},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.States(); !slices.Equal(got, []string{"even", "idle", "odd"}) {
+		t.Fatalf("expected rows with no entries to be kept, got %v", got)
+	}
+	for in, want := range map[string]bool{"": true, "ab": false, "aba": true, "bb": true} {
+		if got := acceptsOrRejects(m, []rune(in)); got != want {
+			t.Errorf("%q: want %v, got %v", in, want, got)
+		}
+	}
+}
+
+func TestNewFromTableAppliesOptions(t *testing.T) {
+	_, err := NewFromTable("A", []string{"A"}, map[string]map[rune]string{
+		"A": {'x': "A"},
+		"B": {'x': "A"},
+	}, WithErrorOnUnreachableStates())
+	if err == nil || !strings.Contains(err.Error(), "unreachable state B") {
+		t.Fatalf("expected unreachable state error, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package fsm
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Equal reports whether a and b are structurally identical: the same initial
+// state, states, alphabet and accepting set, and the same transitions with the
+// same targets. It does not test language equivalence. Two nil machines are
+// equal; a nil and a non-nil machine are not.
+func Equal[S comparable, Sym comparable](a, b *Machine[S, Sym]) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.initialState != b.initialState ||
+		len(a.states) != len(b.states) ||
+		len(a.symbols) != len(b.symbols) ||
+		len(a.accepting) != len(b.accepting) ||
+		len(a.transitions) != len(b.transitions) {
+		return false
+	}
+	for s := range a.states {
+		if _, ok := b.states[s]; !ok {
+			return false
+		}
+	}
+	for sym := range a.symbols {
+		if _, ok := b.symbols[sym]; !ok {
+			return false
+		}
+	}
+	for s := range a.accepting {
+		if _, ok := b.accepting[s]; !ok {
+			return false
+		}
+	}
+	for key, to := range a.transitions {
+		if other, ok := b.transitions[key]; !ok || other != to {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff describes the structural differences between a and b, one line per
+// discrepancy in deterministic order. It returns nil exactly when Equal(a, b)
+// is true.
+func Diff[S comparable, Sym comparable](a, b *Machine[S, Sym]) []string {
+	if a == nil || b == nil {
+		if a == b {
+			return nil
+		}
+		if a == nil {
+			return []string{"first machine is nil"}
+		}
+		return []string{"second machine is nil"}
+	}
+	var out []string
+	if a.initialState != b.initialState {
+		out = append(out, fmt.Sprintf("initial state: %v != %v", a.initialState, b.initialState))
+	}
+	out = appendMembership(out, "state", a.states, b.states)
+	out = appendMembership(out, "symbol", a.symbols, b.symbols)
+
+	accepting := make(map[S]struct{}, len(a.accepting)+len(b.accepting))
+	for s := range a.accepting {
+		accepting[s] = struct{}{}
+	}
+	for s := range b.accepting {
+		accepting[s] = struct{}{}
+	}
+	for _, s := range sortedKeys(accepting) {
+		if inA, inB := a.Accepting(s), b.Accepting(s); inA != inB {
+			out = append(out, fmt.Sprintf("accepting %v: %v != %v", s, inA, inB))
+		}
+	}
+
+	keys := make(map[TransitionKey[S, Sym]]struct{}, len(a.transitions)+len(b.transitions))
+	for key := range a.transitions {
+		keys[key] = struct{}{}
+	}
+	for key := range b.transitions {
+		keys[key] = struct{}{}
+	}
+	sorted := make([]TransitionKey[S, Sym], 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	slices.SortFunc(sorted, func(x, y TransitionKey[S, Sym]) int {
+		if c := compareValues(x.From, y.From); c != 0 {
+			return c
+		}
+		return compareValues(x.Symbol, y.Symbol)
+	})
+	for _, key := range sorted {
+		toA, okA := a.transitions[key]
+		toB, okB := b.transitions[key]
+		switch {
+		case !okB:
+			out = append(out, fmt.Sprintf("transition %v --%v--> %v only in first", key.From, key.Symbol, toA))
+		case !okA:
+			out = append(out, fmt.Sprintf("transition %v --%v--> %v only in second", key.From, key.Symbol, toB))
+		case toA != toB:
+			out = append(out, fmt.Sprintf("transition %v --%v-->: %v != %v", key.From, key.Symbol, toA, toB))
+		}
+	}
+	return out
+}
+
+// appendMembership appends a line to out for every element of only one of a
+// and b, in deterministic order.
+func appendMembership[T comparable](out []string, what string, a, b map[T]struct{}) []string {
+	all := maps.Clone(a)
+	if all == nil {
+		all = make(map[T]struct{}, len(b))
+	}
+	maps.Copy(all, b)
+	for _, x := range sortedKeys(all) {
+		_, inA := a[x]
+		_, inB := b[x]
+		switch {
+		case !inB:
+			out = append(out, fmt.Sprintf("%s %v only in first", what, x))
+		case !inA:
+			out = append(out, fmt.Sprintf("%s %v only in second", what, x))
+		}
+	}
+	return out
+}
+
+// Retarget is a transition whose target differs between two machines.
+type Retarget[S comparable, Sym comparable] struct {
+	From   S
+	Symbol Sym
+	OldTo  S
+	NewTo  S
+}
+
+// MachineDiff lists the changes turning one machine into another. All slices
+// are in deterministic order.
+type MachineDiff[S comparable, Sym comparable] struct {
+	InitialChanged bool
+	OldInitial     S
+	NewInitial     S
+
+	AddedStates   []S
+	RemovedStates []S
+	// BecameAccepting and StoppedAccepting list states present in both
+	// machines whose accepting flag changed, as well as added accepting and
+	// removed accepting states.
+	BecameAccepting  []S
+	StoppedAccepting []S
+
+	AddedTransitions   []Transition[S, Sym]
+	RemovedTransitions []Transition[S, Sym]
+	Retargeted         []Retarget[S, Sym]
+}
+
+// DiffMachines compares old against updated, including declared states
+// without transitions. A nil machine is treated as an empty one whose initial
+// state is the zero value of S.
+func DiffMachines[S comparable, Sym comparable](old, updated *Machine[S, Sym]) MachineDiff[S, Sym] {
+	if old == nil {
+		old = &Machine[S, Sym]{}
+	}
+	if updated == nil {
+		updated = &Machine[S, Sym]{}
+	}
+	d := MachineDiff[S, Sym]{OldInitial: old.initialState, NewInitial: updated.initialState}
+	d.InitialChanged = old.initialState != updated.initialState
+
+	states := make(map[S]struct{}, len(old.states)+len(updated.states))
+	for s := range old.states {
+		states[s] = struct{}{}
+	}
+	for s := range updated.states {
+		states[s] = struct{}{}
+	}
+	for _, s := range sortedKeys(states) {
+		inOld, inNew := old.hasState(s), updated.hasState(s)
+		switch {
+		case !inOld:
+			d.AddedStates = append(d.AddedStates, s)
+		case !inNew:
+			d.RemovedStates = append(d.RemovedStates, s)
+		}
+		switch accOld, accNew := old.Accepting(s), updated.Accepting(s); {
+		case accNew && !accOld:
+			d.BecameAccepting = append(d.BecameAccepting, s)
+		case accOld && !accNew:
+			d.StoppedAccepting = append(d.StoppedAccepting, s)
+		}
+	}
+
+	for t := range old.Transitions() {
+		to, ok := updated.GetTransition(t.From, t.Symbol)
+		switch {
+		case !ok:
+			d.RemovedTransitions = append(d.RemovedTransitions, t)
+		case to != t.To:
+			d.Retargeted = append(d.Retargeted, Retarget[S, Sym]{From: t.From, Symbol: t.Symbol, OldTo: t.To, NewTo: to})
+		}
+	}
+	for t := range updated.Transitions() {
+		if !old.HasTransition(t.From, t.Symbol) {
+			d.AddedTransitions = append(d.AddedTransitions, t)
+		}
+	}
+	return d
+}
+
+// IsEmpty reports whether the machines compared were identical.
+func (d MachineDiff[S, Sym]) IsEmpty() bool {
+	return !d.InitialChanged && len(d.AddedStates) == 0 && len(d.RemovedStates) == 0 &&
+		len(d.BecameAccepting) == 0 && len(d.StoppedAccepting) == 0 &&
+		len(d.AddedTransitions) == 0 && len(d.RemovedTransitions) == 0 && len(d.Retargeted) == 0
+}
+
+// String renders the diff in a unified-diff-like form: lines prefixed with -
+// describe the old machine and lines prefixed with + the new one. An empty
+// diff renders as the empty string.
+func (d MachineDiff[S, Sym]) String() string {
+	if d.IsEmpty() {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("--- old\n+++ new\n")
+	if d.InitialChanged {
+		fmt.Fprintf(&sb, "-initial %v\n+initial %v\n", d.OldInitial, d.NewInitial)
+	}
+	for _, s := range d.RemovedStates {
+		fmt.Fprintf(&sb, "-state %v\n", s)
+	}
+	for _, s := range d.AddedStates {
+		fmt.Fprintf(&sb, "+state %v\n", s)
+	}
+	for _, s := range d.StoppedAccepting {
+		fmt.Fprintf(&sb, "-accepting %v\n", s)
+	}
+	for _, s := range d.BecameAccepting {
+		fmt.Fprintf(&sb, "+accepting %v\n", s)
+	}
+
+	// Transitions are merged into a single listing ordered by source and symbol.
+	type line struct {
+		key  Transition[S, Sym]
+		text string
+	}
+	var lines []line
+	for _, t := range d.RemovedTransitions {
+		lines = append(lines, line{t, fmt.Sprintf("-%v --%v--> %v\n", t.From, t.Symbol, t.To)})
+	}
+	for _, t := range d.AddedTransitions {
+		lines = append(lines, line{t, fmt.Sprintf("+%v --%v--> %v\n", t.From, t.Symbol, t.To)})
+	}
+	for _, r := range d.Retargeted {
+		key := Transition[S, Sym]{From: r.From, Symbol: r.Symbol}
+		lines = append(lines, line{key, fmt.Sprintf("-%v --%v--> %v\n+%v --%v--> %v\n", r.From, r.Symbol, r.OldTo, r.From, r.Symbol, r.NewTo)})
+	}
+	slices.SortFunc(lines, func(a, b line) int { return compareTransitions(a.key, b.key) })
+	for _, l := range lines {
+		sb.WriteString(l.text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package fsm
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEqualStructural(t *testing.T) {
+	a := buildMod3(t)
+	b := buildMod3(t)
+	if !Equal(a, b) {
+		t.Fatalf("expected identically built machines to be equal, diff: %v", Diff(a, b))
+	}
+	if d := Diff(a, b); d != nil {
+		t.Fatalf("expected empty diff, got %v", d)
+	}
+	if !Equal[string, rune](nil, nil) || Equal(a, nil) || Equal(nil, a) {
+		t.Fatalf("unexpected nil handling")
+	}
+
+	minimal := a.Minimize()
+	renamed, _ := minimal.Normalize(nil)
+	if !Equal(minimal, renamed) {
+		t.Fatalf("expected normalized minimal machine to be unchanged")
+	}
+	// Dropping one transition changes the size of the transition map.
+	bld := NewBuilder[string, rune]()
+	bld.AddState("S0", true).AddState("S1", false).AddState("S2", false)
+	bld.SetInitial("S0")
+	bld.On("S0", '0', "S0").On("S0", '1', "S1")
+	bld.On("S1", '0', "S2").On("S1", '1', "S0")
+	bld.On("S2", '0', "S1")
+	partial, err := bld.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if Equal(a, partial) {
+		t.Fatalf("expected machines of differing size to be unequal")
+	}
+}
+
+func TestEqualComparesStatesAndSymbols(t *testing.T) {
+	a := buildMod3(t)
+	b := a.ToBuilder()
+	b.AddState("Extra", false).AddSymbol('2')
+	extended, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if Equal(a, extended) {
+		t.Fatalf("expected an extra state and symbol to make the machines unequal")
+	}
+	want := []string{"state Extra only in second", "symbol 50 only in second"}
+	if got := Diff(a, extended); !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDiffReport(t *testing.T) {
+	build := func(initial string, acceptB bool, target string) *Machine[string, rune] {
+		b := NewBuilder[string, rune]()
+		b.AddState("A", true).AddState("B", acceptB)
+		b.SetInitial(initial)
+		b.On("A", 'x', "B").On("B", 'x', target)
+		if target == "A" {
+			b.On("A", 'y', "A")
+		}
+		m, err := b.Build()
+		if err != nil {
+			t.Fatalf("unexpected build error: %v", err)
+		}
+		return m
+	}
+	a := build("A", false, "A")
+	b := build("B", true, "B")
+	want := []string{
+		"initial state: A != B",
+		"symbol 121 only in first",
+		"accepting B: false != true",
+		"transition A --121--> A only in first",
+		"transition B --120-->: A != B",
+	}
+	if got := Diff(a, b); !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got := Diff(nil, b); !slices.Equal(got, []string{"first machine is nil"}) {
+		t.Fatalf("unexpected nil diff %q", got)
+	}
+}
+
+func TestDiffMachinesReport(t *testing.T) {
+	old := buildMod3(t)
+	b := old.ToBuilder()
+	b.On("S2", '1', "S0") // retarget S2 --1--> S2
+	b.AddState("S1", true)
+	updated, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	d := DiffMachines(old, updated)
+	if d.IsEmpty() {
+		t.Fatalf("expected non-empty diff")
+	}
+	want := `--- old
++++ new
++accepting S1
+-S2 --49--> S2
++S2 --49--> S0
+`
+	if got := d.String(); got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if len(d.Retargeted) != 1 || d.Retargeted[0].NewTo != "S0" {
+		t.Fatalf("unexpected retargets %v", d.Retargeted)
+	}
+
+	same := DiffMachines(old, buildMod3(t))
+	if !same.IsEmpty() || same.String() != "" {
+		t.Fatalf("expected empty diff, got %q", same.String())
+	}
+}
+
+func TestDiffMachinesStatesAndTransitions(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("Gone", true)
+	b.On("A", 'x', "B").On("B", 'y', "A")
+	old, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	b = NewBuilder[string, rune]()
+	b.SetInitial("B")
+	b.AddState("New", false)
+	b.On("A", 'x', "B").On("A", 'y', "A")
+	updated, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	want := `--- old
++++ new
+-initial A
++initial B
+-state Gone
++state New
+-accepting Gone
++A --121--> A
+-B --121--> A
+`
+	if got := DiffMachines(old, updated).String(); got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestDiffMachinesNil(t *testing.T) {
+	m := buildMod3(t)
+	if d := DiffMachines[string, rune](nil, nil); !d.IsEmpty() {
+		t.Fatalf("expected two nil machines to be identical, got %q", d.String())
+	}
+	added := DiffMachines(nil, m)
+	if !added.InitialChanged || added.NewInitial != "S0" || len(added.AddedStates) != 3 || len(added.AddedTransitions) != 6 {
+		t.Fatalf("expected everything in m to be added, got %+v", added)
+	}
+	removed := DiffMachines(m, nil)
+	if len(removed.RemovedStates) != 3 || len(removed.RemovedTransitions) != 6 || len(removed.StoppedAccepting) == 0 {
+		t.Fatalf("expected everything in m to be removed, got %+v", removed)
+	}
+}
@@ -1,22 +1,185 @@
 package fsm
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 )
 
+// ErrEmptyLanguage is returned by operations that cannot produce a meaningful
+// machine because no input is accepted.
+var ErrEmptyLanguage = errors.New("language is empty")
+
+// ErrBuilderFrozen is the panic value, or error, of definition calls on a
+// Builder after a successful Build.
+var ErrBuilderFrozen = errors.New("builder already built; call Clone() or Reset()")
+
+// ErrCyclesTruncated is returned by Cycles alongside the cycles found so far
+// when more cycles exist than the requested limit.
+var ErrCyclesTruncated = errors.New("cycle enumeration truncated")
+
+// ErrMachineExists, ErrMachineNotFound and ErrMachineType are returned by
+// Registry operations.
+var (
+	ErrMachineExists   = errors.New("machine already registered")
+	ErrMachineNotFound = errors.New("machine not registered")
+	ErrMachineType     = errors.New("registered machine has a different type")
+)
+
+// BuildErrorKind classifies a BuildError.
+type BuildErrorKind int
+
+const (
+	// KindOther covers errors without a more specific kind.
+	KindOther BuildErrorKind = iota
+	// KindMissingInitial means no initial state was set.
+	KindMissingInitial
+	// KindNoStates means the definition has no states.
+	KindNoStates
+	// KindNoSymbols means the alphabet is empty.
+	KindNoSymbols
+	// KindUnknownAcceptingState means an accepting state is not a known state.
+	KindUnknownAcceptingState
+	// KindUnknownTransitionState means a transition starts or ends in an
+	// unknown state.
+	KindUnknownTransitionState
+	// KindUnknownSymbol means a transition uses a symbol outside the alphabet.
+	KindUnknownSymbol
+	// KindMissingTransition means a (state, symbol) pair has no transition
+	// under WithRequireTotalTransitions.
+	KindMissingTransition
+	// KindNoAccepting means the accepting set is empty under
+	// WithRequireAtLeastOneAccepting.
+	KindNoAccepting
+	// KindUnreachable means a state cannot be reached from the initial state.
+	KindUnreachable
+	// KindNoAcceptingReachable means no accepting state is reachable.
+	KindNoAcceptingReachable
+	// KindUselessState means a state is unreachable or dead under
+	// WithRequireAllStatesUseful.
+	KindUselessState
+	// KindDeadState means a reachable state cannot reach an accepting state.
+	KindDeadState
+	// KindDeadTransition means a transition can never occur on an accepting run.
+	KindDeadTransition
+	// KindTerminalState means a state has no outgoing transitions.
+	KindTerminalState
+	// KindUnusedSymbol means a symbol has no transition.
+	KindUnusedSymbol
+	// KindNoInitialOutgoing means the initial state has no outgoing transitions.
+	KindNoInitialOutgoing
+	// KindConflict means an import or merge found conflicting definitions.
+	KindConflict
+	// KindUnregistered means a state or symbol was referenced before being
+	// registered under WithStrictRegistration.
+	KindUnregistered
+	// KindInvalidDefinition means a builder call was given unusable arguments.
+	KindInvalidDefinition
+	// KindWarning means a warning was promoted by WithWarningsAsErrors.
+	KindWarning
+	// KindTruncated stands for the errors dropped by WithMaxValidationErrors.
+	KindTruncated
+)
+
+var buildErrorKindNames = [...]string{
+	KindOther:                  "other",
+	KindMissingInitial:         "missing initial state",
+	KindNoStates:               "no states",
+	KindNoSymbols:              "no symbols",
+	KindUnknownAcceptingState:  "unknown accepting state",
+	KindUnknownTransitionState: "unknown transition state",
+	KindUnknownSymbol:          "unknown symbol",
+	KindMissingTransition:      "missing transition",
+	KindNoAccepting:            "no accepting state",
+	KindUnreachable:            "unreachable state",
+	KindNoAcceptingReachable:   "no accepting state reachable",
+	KindUselessState:           "useless state",
+	KindDeadState:              "dead state",
+	KindDeadTransition:         "dead transition",
+	KindTerminalState:          "terminal state",
+	KindUnusedSymbol:           "unused symbol",
+	KindNoInitialOutgoing:      "no initial outgoing transition",
+	KindConflict:               "conflict",
+	KindUnregistered:           "unregistered reference",
+	KindInvalidDefinition:      "invalid definition",
+	KindWarning:                "warning",
+	KindTruncated:              "truncated",
+}
+
+func (k BuildErrorKind) String() string {
+	if k >= 0 && int(k) < len(buildErrorKindNames) {
+		return buildErrorKindNames[k]
+	}
+	return fmt.Sprintf("BuildErrorKind(%d)", int(k))
+}
+
+// Sentinels matching every BuildError of their kind with errors.Is, also
+// through ValidationErrors.
+var (
+	ErrMissingInitial         = newSentinel(KindMissingInitial)
+	ErrNoStates               = newSentinel(KindNoStates)
+	ErrNoSymbols              = newSentinel(KindNoSymbols)
+	ErrUnknownAcceptingState  = newSentinel(KindUnknownAcceptingState)
+	ErrUnknownTransitionState = newSentinel(KindUnknownTransitionState)
+	ErrUnknownSymbol          = newSentinel(KindUnknownSymbol)
+	ErrMissingTransition      = newSentinel(KindMissingTransition)
+	ErrNoAccepting            = newSentinel(KindNoAccepting)
+	ErrUnreachable            = newSentinel(KindUnreachable)
+	ErrNoAcceptingReachable   = newSentinel(KindNoAcceptingReachable)
+	ErrUselessState           = newSentinel(KindUselessState)
+	ErrDeadState              = newSentinel(KindDeadState)
+	ErrDeadTransition         = newSentinel(KindDeadTransition)
+	ErrTerminalState          = newSentinel(KindTerminalState)
+	ErrUnusedSymbol           = newSentinel(KindUnusedSymbol)
+	ErrNoInitialOutgoing      = newSentinel(KindNoInitialOutgoing)
+	ErrConflict               = newSentinel(KindConflict)
+	ErrUnregistered           = newSentinel(KindUnregistered)
+	ErrInvalidDefinition      = newSentinel(KindInvalidDefinition)
+	ErrWarning                = newSentinel(KindWarning)
+	ErrTruncated              = newSentinel(KindTruncated)
+)
+
+// BuildError is a single problem found while building a machine. State, Symbol
+// and Target hold the values involved, when the kind has them; they are nil
+// otherwise.
 type BuildError struct {
-	message string
+	Kind   BuildErrorKind
+	State  any
+	Symbol any
+	Target any
+
+	message  string
+	sentinel bool
 }
 
 func (e *BuildError) Error() string { return e.message }
 
-func newBuildError(format string, args ...any) error {
-	return &BuildError{message: fmt.Sprintf(format, args...)}
+// Is reports whether target is the sentinel of e's kind.
+func (e *BuildError) Is(target error) bool {
+	t, ok := target.(*BuildError)
+	return ok && t.sentinel && t.Kind == e.Kind
+}
+
+func newBuildError(kind BuildErrorKind, format string, args ...any) *BuildError {
+	return &BuildError{Kind: kind, message: fmt.Sprintf(format, args...)}
+}
+
+func newSentinel(kind BuildErrorKind) error {
+	return &BuildError{Kind: kind, message: kind.String(), sentinel: true}
 }
 
+// withState, withSymbol and withTarget fill in the values involved in e.
+func (e *BuildError) withState(state any) *BuildError   { e.State = state; return e }
+func (e *BuildError) withSymbol(symbol any) *BuildError { e.Symbol = symbol; return e }
+func (e *BuildError) withTarget(target any) *BuildError { e.Target = target; return e }
+
 type ValidationErrors struct {
 	errors []error
+	// limit caps len(errors) when positive; truncated counts the errors
+	// dropped past it.
+	limit     int
+	truncated int
 }
 
 func (ve *ValidationErrors) Error() string {
@@ -39,27 +202,154 @@ func (ve *ValidationErrors) Append(err error) {
 	if err == nil {
 		return
 	}
+	if ve.limit > 0 && len(ve.errors) >= ve.limit {
+		ve.truncated++
+		return
+	}
 	ve.errors = append(ve.errors, err)
 }
 
+// Unwrap returns the collected errors, so errors.Is and errors.As look inside
+// them.
+func (ve *ValidationErrors) Unwrap() []error {
+	return ve.errors
+}
+
+// Errors returns a copy of the collected errors.
+func (ve *ValidationErrors) Errors() []error {
+	return slices.Clone(ve.errors)
+}
+
 func (ve *ValidationErrors) IsEmpty() bool { return len(ve.errors) == 0 }
 
+// AsError returns ve itself, as an error, or nil if it is empty.
 func (ve *ValidationErrors) AsError() error {
 	if ve.IsEmpty() {
 		return nil
 	}
+	if ve.truncated > 0 {
+		ve.errors = append(ve.errors, newBuildError(KindTruncated, "… and %d more errors (truncated)", ve.truncated))
+		ve.truncated = 0
+	}
 	return ve
 }
 
-type TransitionError struct {
-	From   any
-	Symbol any
+// named formats value with namer when printed with %v, falling back to the
+// plain value when namer panics.
+type named struct {
+	value any
+	namer func(any) string
 }
 
-func (e *TransitionError) Error() string {
-	return fmt.Sprintf("no transition from %v on %v", e.From, e.Symbol)
+func (n named) String() (name string) {
+	defer func() {
+		if recover() != nil {
+			name = fmt.Sprint(n.value)
+		}
+	}()
+	return n.namer(n.value)
 }
 
-//
+// nameWith returns value wrapped to print through namer, or value itself when
+// namer is nil.
+func nameWith(namer func(any) string, value any) any {
+	if namer == nil {
+		return value
+	}
+	return named{value: value, namer: namer}
+}
 
+// TransitionError reports a symbol that cannot be consumed from a state.
+type TransitionError[S, Sym any] struct {
+	From   S
+	Symbol Sym
+	// Position is the index of Symbol within the evaluated input, or -1 when
+	// the error does not come from evaluating an input, as for Runner.Step.
+	Position int
 
+	// Machine.Explain of the machine that failed, set by Runner.Step.
+	explain func(S, Sym) Explanation[S, Sym]
+	// Namers of the machine, from WithStateNamer and WithSymbolNamer.
+	stateNamer, symbolNamer func(any) string
+}
+
+// Explain explains the failed lookup, as Machine.Explain does. The
+// explanation is computed on demand; ok is false when the error does not come
+// from Runner.Step.
+func (e *TransitionError[S, Sym]) Explain() (_ Explanation[S, Sym], ok bool) {
+	if e.explain == nil {
+		return Explanation[S, Sym]{}, false
+	}
+	return e.explain(e.From, e.Symbol), true
+}
+
+func (e *TransitionError[S, Sym]) Error() string {
+	from, sym := nameWith(e.stateNamer, e.From), nameWith(e.symbolNamer, e.Symbol)
+	if e.Position >= 0 {
+		return fmt.Sprintf("no transition from %v on %v at position %d", from, sym, e.Position)
+	}
+	return fmt.Sprintf("no transition from %v on %v", from, sym)
+}
+
+// InterruptedError reports an evaluation stopped because its context was
+// done. State and Index give the state reached and the index of the next
+// symbol to read, so the evaluation can be resumed with Machine.StartAt.
+type InterruptedError[S any] struct {
+	State S
+	Index int
+	Err   error
+
+	// Namer of the machine, from WithStateNamer.
+	stateNamer func(any) string
+}
+
+func (e *InterruptedError[S]) Error() string {
+	return fmt.Sprintf("interrupted in state %v at index %d: %v", nameWith(e.stateNamer, e.State), e.Index, e.Err)
+}
+
+// Unwrap returns the context error.
+func (e *InterruptedError[S]) Unwrap() error { return e.Err }
+
+type NoAcceptedStringError struct {
+	Length int
+}
+
+func (e *NoAcceptedStringError) Error() string {
+	return fmt.Sprintf("no accepted input of length %d", e.Length)
+}
+
+type UnknownStateError struct {
+	State any
+}
+
+func (e *UnknownStateError) Error() string {
+	return fmt.Sprintf("unknown state %v", e.State)
+}
+
+type NoCompletionError struct {
+	MaxLen int
+}
+
+func (e *NoCompletionError) Error() string {
+	if e.MaxLen < 0 {
+		return "no completion exists"
+	}
+	return fmt.Sprintf("no completion within %d symbols", e.MaxLen)
+}
+
+// DuplicateTransitionError reports a transition defined a second time, as
+// returned by Builder.OnChecked and collected under WithCollectOverwriteErrors.
+type DuplicateTransitionError struct {
+	From     any
+	Symbol   any
+	Existing any
+	New      any
+
+	// Namers of the builder, from WithStateNamer and WithSymbolNamer.
+	stateNamer, symbolNamer func(any) string
+}
+
+func (e *DuplicateTransitionError) Error() string {
+	return fmt.Sprintf("transition already defined for (%v,%v): leads to %v, redefined to %v",
+		nameWith(e.stateNamer, e.From), nameWith(e.symbolNamer, e.Symbol), nameWith(e.stateNamer, e.Existing), nameWith(e.stateNamer, e.New))
+}
@@ -1,6 +1,10 @@
 package fsm
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
 
 func TestTransitionErrorWhenMissing(t *testing.T) {
 	b := NewBuilder[string, rune]()
@@ -44,11 +48,11 @@ func TestValidationErrorsFormatting(t *testing.T) {
 	if got := ve.Error(); got != "no validation errors" {
 		t.Fatalf("empty ValidationErrors message mismatch: %q", got)
 	}
-	ve.Append(newBuildError("a"))
+	ve.Append(newBuildError(KindOther, "a"))
 	if got := ve.Error(); got != "a" {
 		t.Fatalf("single ValidationError message mismatch: %q", got)
 	}
-	ve.Append(newBuildError("b"))
+	ve.Append(newBuildError(KindOther, "b"))
 	msg := ve.Error()
 	if msg == "a" || msg == "no validation errors" {
 		t.Fatalf("expected multi-error message, got %q", msg)
@@ -63,7 +67,7 @@ func TestValidationAsErrorAndIsEmpty(t *testing.T) {
 	if ve.AsError() != nil {
 		t.Fatalf("expected nil error for empty ValidationErrors")
 	}
-	ve.Append(newBuildError("x"))
+	ve.Append(newBuildError(KindOther, "x"))
 	if ve.IsEmpty() {
 		t.Fatalf("expected non-empty after append")
 	}
@@ -81,3 +85,174 @@ func TestValidationAppendNilError(t *testing.T) {
 }
 
 
+
+func TestBuildErrorKindsMatchSentinels(t *testing.T) {
+	b := NewBuilder[string, rune](WithRequireTotalTransitions(), WithErrorOnUnreachableStates())
+	b.SetInitial("A").AddState("B", true)
+	b.On("A", 'x', "B").SelfLoop("B", 'x', 'y').SelfLoop("C", 'x', 'y')
+	_, err := b.Build()
+	if err == nil {
+		t.Fatalf("expected build errors")
+	}
+	for _, sentinel := range []error{ErrMissingTransition, ErrUnreachable} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("expected errors.Is(err, %v)", sentinel)
+		}
+	}
+	for _, sentinel := range []error{ErrMissingInitial, ErrDeadState, ErrNoSymbols} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("did not expect errors.Is(err, %v)", sentinel)
+		}
+	}
+	var berr *BuildError
+	if !errors.As(err, &berr) || berr.Kind != KindMissingTransition || berr.State != "A" || berr.Symbol != 'y' {
+		t.Fatalf("expected the first error to be A's missing transition on y, got %#v", berr)
+	}
+	if berr.Error() != "missing transition from A on 121" {
+		t.Fatalf("expected the message to be unchanged, got %q", berr.Error())
+	}
+}
+
+func TestBuildErrorKindOnTransitionChecks(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A").AddSymbol('x')
+	b.transitions[TransitionKey[string, rune]{From: "A", Symbol: 'x'}] = "Z"
+	_, err := b.Build()
+	var berr *BuildError
+	if !errors.As(err, &berr) || !errors.Is(err, ErrUnknownTransitionState) {
+		t.Fatalf("expected an unknown transition state error, got %v", err)
+	}
+	if berr.State != "A" || berr.Symbol != 'x' || berr.Target != "Z" {
+		t.Fatalf("expected the transition's values, got %#v", berr)
+	}
+}
+
+func TestBuildErrorKindString(t *testing.T) {
+	if got := KindMissingTransition.String(); got != "missing transition" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := BuildErrorKind(99).String(); got != "BuildErrorKind(99)" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := ErrDeadState.Error(); got != "dead state" {
+		t.Fatalf("unexpected sentinel message %q", got)
+	}
+}
+
+func TestValidationErrorsUnwrap(t *testing.T) {
+	b := NewBuilder[string, rune](WithRequireAtLeastOneAccepting())
+	b.AddState("A", false)
+	_, err := b.Build()
+	var verr *ValidationErrors
+	if !errors.As(err, &verr) || verr.AsError() != error(verr) {
+		t.Fatalf("expected Build to return the *ValidationErrors itself, got %T", err)
+	}
+	if got := len(verr.Unwrap()); got != 3 {
+		t.Fatalf("expected 3 wrapped errors, got %d: %v", got, err)
+	}
+	if !errors.Is(err, ErrNoSymbols) || !errors.Is(err, ErrNoAccepting) || errors.Is(err, ErrMissingTransition) {
+		t.Fatalf("unexpected errors.Is results for %v", err)
+	}
+	var berr *BuildError
+	if !errors.As(err, &berr) || berr.Kind != KindMissingInitial {
+		t.Fatalf("expected errors.As to extract the first *BuildError, got %#v", berr)
+	}
+
+	errs := verr.Errors()
+	errs[0] = nil
+	if verr.Errors()[0] == nil {
+		t.Fatalf("expected Errors to return a copy")
+	}
+}
+
+func TestTransitionErrorIsTypedWithPosition(t *testing.T) {
+	b := NewBuilder[int, byte]()
+	b.SetInitial(0).SelfLoop(0, 'a').AddSymbol('b')
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	_, err = m.Eval([]byte("aab"))
+	var terr *TransitionError[int, byte]
+	if !errors.As(err, &terr) {
+		t.Fatalf("expected *TransitionError[int, byte], got %T", err)
+	}
+	if terr.From != 0 || terr.Symbol != 'b' || terr.Position != 2 {
+		t.Fatalf("unexpected error fields %+v", terr)
+	}
+	if got := err.Error(); got != "no transition from 0 on 98 at position 2" {
+		t.Fatalf("unexpected message %q", got)
+	}
+
+	err = m.Start().Step('b')
+	if !errors.As(err, &terr) || terr.Position != -1 {
+		t.Fatalf("expected a Step error without position, got %v", err)
+	}
+	if got := err.Error(); got != "no transition from 0 on 98" {
+		t.Fatalf("unexpected message %q", got)
+	}
+}
+
+// cell is a struct state that prints as an unreadable blob with %v.
+type cell struct {
+	Row, Col int
+	Open     bool
+}
+
+func TestStateNamerInBuildAndRuntimeErrors(t *testing.T) {
+	namer := func(v any) string {
+		c := v.(cell)
+		return fmt.Sprintf("r%dc%d", c.Row, c.Col)
+	}
+	symNamer := func(v any) string { return string(v.(rune)) }
+	start, goal, lost := cell{0, 0, true}, cell{0, 1, true}, cell{5, 5, false}
+	b := NewBuilder[cell, rune](WithStateNamer(namer), WithSymbolNamer(symNamer), WithErrorOnUnreachableStates())
+	b.SetInitial(start).AddState(goal, true).AddState(lost, false)
+	b.On(start, 'e', goal)
+	_, err := b.Build()
+	if err == nil || err.Error() != "unreachable state r5c5" {
+		t.Fatalf("expected a named unreachable state, got %v", err)
+	}
+
+	b.RemoveState(lost)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	_, err = m.Eval([]rune("ee"))
+	if err == nil || err.Error() != "no transition from r0c1 on e at position 1" {
+		t.Fatalf("expected named states and symbols at runtime, got %v", err)
+	}
+}
+
+func TestStateNamerPanicFallsBack(t *testing.T) {
+	b := NewBuilder[cell, rune](WithStateNamer(func(any) string { panic("boom") }), WithErrorOnUnreachableStates())
+	b.SetInitial(cell{}).AddState(cell{1, 2, false}, false).AddSymbol('x')
+	if _, err := b.Build(); err == nil || err.Error() != "unreachable state {1 2 false}" {
+		t.Fatalf("expected the %%v fallback, got %v", err)
+	}
+}
+
+func TestStateNamerInDuplicateTransitions(t *testing.T) {
+	namer := func(v any) string {
+		c := v.(cell)
+		return fmt.Sprintf("r%dc%d", c.Row, c.Col)
+	}
+	opts := []Option{WithStateNamer(namer), WithSymbolNamer(func(v any) string { return string(v.(rune)) })}
+	start, goal, other := cell{0, 0, true}, cell{0, 1, true}, cell{1, 0, true}
+	b := NewBuilder[cell, rune](opts...)
+	b.On(start, 'e', goal)
+	err := b.OnChecked(start, 'e', other)
+	if err == nil || err.Error() != "transition already defined for (r0c0,e): leads to r0c1, redefined to r1c0" {
+		t.Fatalf("expected a named duplicate transition, got %v", err)
+	}
+
+	prevent := NewBuilder[cell, rune](append(opts, WithPreventOverwriteTransitions())...)
+	prevent.On(start, 'e', goal)
+	defer func() {
+		if got := recover(); got != "transition already defined for (r0c0,e)" {
+			t.Fatalf("expected a named panic, got %v", got)
+		}
+	}()
+	prevent.On(start, 'e', other)
+}
@@ -0,0 +1,74 @@
+package fsm
+
+import "fmt"
+
+// Reason classifies the outcome of looking up a transition.
+type Reason int
+
+const (
+	// TransitionDefined means the transition exists.
+	TransitionDefined Reason = iota
+	// UnknownState means the source state does not belong to the machine.
+	UnknownState
+	// UnknownSymbol means the symbol is not in the machine's alphabet.
+	UnknownSymbol
+	// UndefinedTransition means state and symbol are known but the machine has
+	// no transition for the pair.
+	UndefinedTransition
+)
+
+func (r Reason) String() string {
+	switch r {
+	case TransitionDefined:
+		return "transition defined"
+	case UnknownState:
+		return "unknown state"
+	case UnknownSymbol:
+		return "unknown symbol"
+	case UndefinedTransition:
+		return "undefined transition"
+	}
+	return fmt.Sprintf("Reason(%d)", int(r))
+}
+
+// Explanation describes why a transition lookup succeeds or fails.
+type Explanation[S, Sym any] struct {
+	From   S
+	Symbol Sym
+	Reason Reason
+	// To is the target state when Reason is TransitionDefined.
+	To S
+	// Defined lists, in deterministic order, the symbols with a transition from
+	// From; it is empty for unknown states.
+	Defined []Sym
+}
+
+func (e Explanation[S, Sym]) String() string {
+	switch e.Reason {
+	case TransitionDefined:
+		return fmt.Sprintf("%v --%v--> %v", e.From, e.Symbol, e.To)
+	case UnknownState:
+		return fmt.Sprintf("no transition from %v on %v: unknown state", e.From, e.Symbol)
+	}
+	return fmt.Sprintf("no transition from %v on %v: %v; defined symbols: %v", e.From, e.Symbol, e.Reason, e.Defined)
+}
+
+// Explain looks up the transition from state from on sym and reports the
+// outcome. When both the state and the symbol are unknown, UnknownState wins.
+func (m *Machine[S, Sym]) Explain(from S, sym Sym) Explanation[S, Sym] {
+	e := Explanation[S, Sym]{From: from, Symbol: sym, Defined: m.DefinedSymbols(from)}
+	if to, ok := m.GetTransition(from, sym); ok {
+		e.Reason, e.To = TransitionDefined, to
+		return e
+	}
+	if !m.hasState(from) {
+		e.Reason = UnknownState
+		return e
+	}
+	if _, ok := m.symbols[sym]; !ok {
+		e.Reason = UnknownSymbol
+		return e
+	}
+	e.Reason = UndefinedTransition
+	return e
+}
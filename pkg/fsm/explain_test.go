@@ -0,0 +1,72 @@
+package fsm
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestExplainReasons(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddSymbol('z')
+	b.On("A", 'y', "B").On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	cases := []struct {
+		from    string
+		sym     rune
+		reason  Reason
+		defined []rune
+	}{
+		{"A", 'x', TransitionDefined, []rune{'x', 'y'}},
+		{"A", 'z', UndefinedTransition, []rune{'x', 'y'}},
+		{"A", 'q', UnknownSymbol, []rune{'x', 'y'}},
+		{"B", 'x', UndefinedTransition, []rune{}},
+		{"Nope", 'x', UnknownState, []rune{}},
+		{"Nope", 'q', UnknownState, []rune{}},
+	}
+	for _, tc := range cases {
+		e := m.Explain(tc.from, tc.sym)
+		if e.Reason != tc.reason || !slices.Equal(e.Defined, tc.defined) {
+			t.Fatalf("Explain(%s, %q): expected %v %q, got %v %q", tc.from, tc.sym, tc.reason, tc.defined, e.Reason, e.Defined)
+		}
+	}
+	if e := m.Explain("A", 'x'); e.To != "A" {
+		t.Fatalf("expected target A, got %v", e.To)
+	}
+	if got, want := m.Explain("A", 'z').String(), "no transition from A on 122: undefined transition; defined symbols: [120 121]"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestStepAttachesExplanation(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddSymbol('z')
+	b.On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	_, err = m.Eval([]rune{'x', 'z'})
+	var te *TransitionError[string, rune]
+	if !errors.As(err, &te) {
+		t.Fatalf("expected TransitionError, got %v", err)
+	}
+	e, ok := te.Explain()
+	if !ok {
+		t.Fatalf("expected an explanation")
+	}
+	if e.Reason != UndefinedTransition || !slices.Equal(e.Defined, []rune{'x'}) {
+		t.Fatalf("unexpected explanation %+v", e)
+	}
+	if err.Error() != "no transition from A on 122 at position 1" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if _, ok := (&TransitionError[string, rune]{}).Explain(); ok {
+		t.Fatalf("expected no explanation for an error not from Step")
+	}
+}
@@ -0,0 +1,100 @@
+package fsm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint returns a hex-encoded SHA-256 digest of a canonical rendering of
+// the machine: its sorted states, sorted symbols, sorted transitions, initial
+// state and accepting states. Machines with identical content have identical
+// fingerprints regardless of the order in which they were built.
+//
+// States and symbols are rendered with %v, which is only done for string,
+// integer and boolean types; any other type yields an error and must be
+// fingerprinted with FingerprintFunc.
+func (m *Machine[S, Sym]) Fingerprint() (string, error) {
+	formatState, err := defaultFingerprintFormat[S]()
+	if err != nil {
+		return "", err
+	}
+	formatSym, err := defaultFingerprintFormat[Sym]()
+	if err != nil {
+		return "", err
+	}
+	return m.FingerprintFunc(formatState, formatSym)
+}
+
+// FingerprintFunc is like Fingerprint but renders states and symbols with the
+// given functions. An error is returned if a function maps two distinct values
+// to the same text, since the digest would then be ambiguous.
+func (m *Machine[S, Sym]) FingerprintFunc(formatState func(S) string, formatSym func(Sym) string) (string, error) {
+	stateNames, err := fingerprintNames(m.States(), formatState)
+	if err != nil {
+		return "", err
+	}
+	symNames, err := fingerprintNames(m.Symbols(), formatSym)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	writeSection := func(name string, lines []string) {
+		slices.Sort(lines)
+		fmt.Fprintf(&sb, "%s %d\n", name, len(lines))
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+		}
+	}
+	writeSection("states", stateNames)
+	writeSection("symbols", symNames)
+	var transitions []string
+	for key, to := range m.transitions {
+		transitions = append(transitions, strconv.Quote(formatState(key.From))+" "+
+			strconv.Quote(formatSym(key.Symbol))+" "+strconv.Quote(formatState(to)))
+	}
+	writeSection("transitions", transitions)
+	writeSection("initial", []string{strconv.Quote(formatState(m.initialState))})
+	var accepting []string
+	for s := range m.accepting {
+		accepting = append(accepting, strconv.Quote(formatState(s)))
+	}
+	writeSection("accepting", accepting)
+
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// fingerprintNames renders and quotes values, rejecting collisions.
+func fingerprintNames[T comparable](values []T, format func(T) string) ([]string, error) {
+	seen := make(map[string]T, len(values))
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		name := format(v)
+		if prev, ok := seen[name]; ok && prev != v {
+			return nil, fmt.Errorf("distinct values %#v and %#v format as %q", prev, v, name)
+		}
+		seen[name] = v
+		names = append(names, strconv.Quote(name))
+	}
+	return names, nil
+}
+
+// defaultFingerprintFormat returns %v formatting for types where it is
+// unambiguous, and an error otherwise.
+func defaultFingerprintFormat[T any]() (func(T) string, error) {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(v T) string { return fmt.Sprintf("%v", v) }, nil
+	}
+	var zero T
+	return nil, fmt.Errorf("no fingerprint format for type %T, use FingerprintFunc", zero)
+}
@@ -0,0 +1,88 @@
+package fsm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFingerprintInsertionOrderIndependent(t *testing.T) {
+	type edge struct {
+		from string
+		sym  rune
+		to   string
+	}
+	edges := []edge{
+		{"S0", '0', "S0"}, {"S0", '1', "S1"},
+		{"S1", '0', "S2"}, {"S1", '1', "S0"},
+		{"S2", '0', "S1"}, {"S2", '1', "S2"},
+	}
+	build := func(order []int) *Machine[string, rune] {
+		b := NewBuilder[string, rune]()
+		for _, i := range order {
+			e := edges[i]
+			b.On(e.from, e.sym, e.to)
+		}
+		b.AddState("S0", true)
+		b.SetInitial("S0")
+		m, err := b.Build()
+		if err != nil {
+			t.Fatalf("unexpected build error: %v", err)
+		}
+		return m
+	}
+	want, err := build([]int{0, 1, 2, 3, 4, 5}).Fingerprint()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(want) != 64 {
+		t.Fatalf("expected hex SHA-256, got %q", want)
+	}
+	for i := 0; i < 20; i++ {
+		got, err := build([]int{5, 3, 1, 4, 2, 0}).Fingerprint()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	}
+
+	if other, _ := buildMod3(t).Fingerprint(); other != want {
+		t.Fatalf("expected mod3 built via AddState to match, got %s and %s", want, other)
+	}
+	changed := NewBuilder[string, rune]()
+	changed.AddState("S1", true)
+	changed.SetInitial("S0")
+	for _, e := range edges {
+		changed.On(e.from, e.sym, e.to)
+	}
+	m, err := changed.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, _ := m.Fingerprint(); got == want {
+		t.Fatalf("expected different accepting set to change the fingerprint")
+	}
+}
+
+func TestFingerprintRequiresFormatter(t *testing.T) {
+	type point struct{ X, Y int }
+	b := NewBuilder[point, rune]()
+	b.SetInitial(point{0, 0})
+	b.On(point{0, 0}, 'x', point{1, 0})
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if _, err := m.Fingerprint(); err == nil {
+		t.Fatalf("expected error for struct states without a formatter")
+	}
+	format := func(p point) string { return fmt.Sprintf("%d,%d", p.X, p.Y) }
+	sym := func(r rune) string { return string(r) }
+	if _, err := m.FingerprintFunc(format, sym); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := m.FingerprintFunc(func(point) string { return "p" }, sym); err == nil {
+		t.Fatalf("expected error for colliding formatter")
+	}
+}
@@ -0,0 +1,69 @@
+package fsm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Format implements fmt.Formatter. The %v and %s verbs print a one-line
+// summary; %+v adds the accepting set and every transition, one per line; %#v
+// prints Builder calls that reconstruct the machine. Output is deterministic.
+func (m *Machine[S, Sym]) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+	default:
+		fmt.Fprintf(f, "%%!%c(*fsm.Machine)", verb)
+		return
+	}
+	if m == nil {
+		fmt.Fprint(f, "<nil>")
+		return
+	}
+	if verb == 'v' && f.Flag('#') {
+		m.formatGo(f)
+		return
+	}
+	fmt.Fprintf(f, "Machine{states: %d, symbols: %d, transitions: %d, initial: %v, accepting: %d}",
+		len(m.stateList), len(m.symbols), len(m.transitions), m.initialState, len(m.accepting))
+	if verb != 'v' || !f.Flag('+') {
+		return
+	}
+	fmt.Fprintf(f, "\naccepting: %v", sortedKeys(m.accepting))
+	for t := range m.Transitions() {
+		fmt.Fprintf(f, "\n%v --%v--> %v", t.From, t.Symbol, t.To)
+	}
+}
+
+// formatGo writes a chain of Builder calls reconstructing the machine.
+func (m *Machine[S, Sym]) formatGo(f fmt.State) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "fsm.NewBuilder[%s, %s]().\n", reflect.TypeFor[S](), reflect.TypeFor[Sym]())
+	fmt.Fprintf(&sb, "\tSetInitial(%#v)", m.initialState)
+	for _, s := range m.stateList {
+		fmt.Fprintf(&sb, ".\n\tAddState(%#v, %t)", s, m.Accepting(s))
+	}
+	for _, sym := range m.sortedSymbols() {
+		fmt.Fprintf(&sb, ".\n\tAddSymbol(%#v)", sym)
+	}
+	for t := range m.Transitions() {
+		fmt.Fprintf(&sb, ".\n\tOn(%#v, %#v, %#v)", t.From, t.Symbol, t.To)
+	}
+	fmt.Fprint(f, sb.String())
+}
+
+// Format implements fmt.Formatter, printing the current state and whether it
+// is accepting for the %v and %s verbs.
+func (r *Runner[S, Sym]) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+	default:
+		fmt.Fprintf(f, "%%!%c(*fsm.Runner)", verb)
+		return
+	}
+	if r == nil {
+		fmt.Fprint(f, "<nil>")
+		return
+	}
+	fmt.Fprintf(f, "Runner{state: %v, accepting: %t}", r.state, r.machine.Accepting(r.state))
+}
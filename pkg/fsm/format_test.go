@@ -0,0 +1,67 @@
+package fsm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMachineFormatSummary(t *testing.T) {
+	m := buildMod3(t)
+	want := "Machine{states: 3, symbols: 2, transitions: 6, initial: S0, accepting: 1}"
+	if got := fmt.Sprintf("%v", m); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got := fmt.Sprint(m); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMachineFormatTable(t *testing.T) {
+	m := buildMod3(t)
+	want := `Machine{states: 3, symbols: 2, transitions: 6, initial: S0, accepting: 1}
+accepting: [S0]
+S0 --48--> S0
+S0 --49--> S1
+S1 --48--> S2
+S1 --49--> S0
+S2 --48--> S1
+S2 --49--> S2`
+	for i := 0; i < 5; i++ {
+		if got := fmt.Sprintf("%+v", m); got != want {
+			t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+		}
+	}
+}
+
+func TestMachineFormatGoSyntax(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'x', "B")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	want := `fsm.NewBuilder[string, int32]().
+	SetInitial("A").
+	AddState("A", false).
+	AddState("B", true).
+	AddSymbol(120).
+	On("A", 120, "B")`
+	if got := fmt.Sprintf("%#v", m); got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRunnerFormat(t *testing.T) {
+	r := buildMod3(t).Start()
+	if got, want := fmt.Sprintf("%v", r), "Runner{state: S0, accepting: true}"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if err := r.Step('1'); err != nil {
+		t.Fatalf("unexpected step error: %v", err)
+	}
+	if got, want := fmt.Sprintf("%s", r), "Runner{state: S1, accepting: false}"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,148 @@
+// Package fsmgraph adapts fsm machines to gonum's graph interfaces so that
+// gonum's traversal, path and topology algorithms can run on a transition
+// graph directly.
+package fsmgraph
+
+import (
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
+
+	"github.com/bohdan-natsevych/fsm-generator/pkg/fsm"
+)
+
+// Node is a machine state with a stable int64 id.
+type Node[S comparable] struct {
+	id    int64
+	State S
+}
+
+// ID returns the node id.
+func (n Node[S]) ID() int64 { return n.id }
+
+// Edge connects two states and carries every symbol leading from one to the
+// other, in deterministic order. Parallel transitions on different symbols
+// collapse into a single Edge.
+type Edge[S comparable, Sym comparable] struct {
+	F, T    Node[S]
+	Symbols []Sym
+}
+
+// From returns the source node.
+func (e Edge[S, Sym]) From() graph.Node { return e.F }
+
+// To returns the target node.
+func (e Edge[S, Sym]) To() graph.Node { return e.T }
+
+// ReversedEdge returns the edge with its endpoints swapped.
+func (e Edge[S, Sym]) ReversedEdge() graph.Edge {
+	return Edge[S, Sym]{F: e.T, T: e.F, Symbols: e.Symbols}
+}
+
+// Graph is a read-only graph.Directed view of a Machine. The initial state has
+// id 0, states reachable from it are numbered in breadth-first order, and the
+// remaining states follow in the order of Machine.SCCs.
+type Graph[S comparable, Sym comparable] struct {
+	nodes []graph.Node
+	ids   map[S]int64
+	from  map[int64][]graph.Node
+	to    map[int64][]graph.Node
+	edges map[[2]int64]Edge[S, Sym]
+}
+
+var _ graph.Directed = (*Graph[string, rune])(nil)
+
+// New builds the adapter for m. The machine is immutable, so the graph is
+// computed once.
+func New[S comparable, Sym comparable](m *fsm.Machine[S, Sym]) *Graph[S, Sym] {
+	g := &Graph[S, Sym]{
+		ids:   make(map[S]int64),
+		from:  make(map[int64][]graph.Node),
+		to:    make(map[int64][]graph.Node),
+		edges: make(map[[2]int64]Edge[S, Sym]),
+	}
+	add := func(s S) {
+		if _, ok := g.ids[s]; !ok {
+			g.ids[s] = int64(len(g.nodes))
+			g.nodes = append(g.nodes, Node[S]{id: int64(len(g.nodes)), State: s})
+		}
+	}
+	for _, s := range m.BFSOrder() {
+		add(s)
+	}
+	for _, comp := range m.SCCs() {
+		for _, s := range comp {
+			add(s)
+		}
+	}
+	for _, t := range m.EdgeList() {
+		u, v := g.ids[t.From], g.ids[t.To]
+		key := [2]int64{u, v}
+		e, ok := g.edges[key]
+		if !ok {
+			e = Edge[S, Sym]{F: g.nodes[u].(Node[S]), T: g.nodes[v].(Node[S])}
+			g.from[u] = append(g.from[u], g.nodes[v])
+			g.to[v] = append(g.to[v], g.nodes[u])
+		}
+		e.Symbols = append(e.Symbols, t.Symbol)
+		g.edges[key] = e
+	}
+	return g
+}
+
+// NodeFor returns the node of state, if the state belongs to the machine.
+func (g *Graph[S, Sym]) NodeFor(state S) (Node[S], bool) {
+	id, ok := g.ids[state]
+	if !ok {
+		return Node[S]{}, false
+	}
+	return g.nodes[id].(Node[S]), true
+}
+
+// Node returns the node with the given id, or nil if there is none.
+func (g *Graph[S, Sym]) Node(id int64) graph.Node {
+	if id < 0 || id >= int64(len(g.nodes)) {
+		return nil
+	}
+	return g.nodes[id]
+}
+
+// Nodes returns all nodes in id order.
+func (g *Graph[S, Sym]) Nodes() graph.Nodes {
+	return iterator.NewOrderedNodes(g.nodes)
+}
+
+// From returns the nodes reachable in one transition from the node with id.
+func (g *Graph[S, Sym]) From(id int64) graph.Nodes {
+	if len(g.from[id]) == 0 {
+		return graph.Empty
+	}
+	return iterator.NewOrderedNodes(g.from[id])
+}
+
+// To returns the nodes with a transition into the node with id.
+func (g *Graph[S, Sym]) To(id int64) graph.Nodes {
+	if len(g.to[id]) == 0 {
+		return graph.Empty
+	}
+	return iterator.NewOrderedNodes(g.to[id])
+}
+
+// HasEdgeBetween reports whether a transition connects the two nodes in either direction.
+func (g *Graph[S, Sym]) HasEdgeBetween(xid, yid int64) bool {
+	return g.HasEdgeFromTo(xid, yid) || g.HasEdgeFromTo(yid, xid)
+}
+
+// HasEdgeFromTo reports whether a transition leads from node uid to node vid.
+func (g *Graph[S, Sym]) HasEdgeFromTo(uid, vid int64) bool {
+	_, ok := g.edges[[2]int64{uid, vid}]
+	return ok
+}
+
+// Edge returns the Edge from node uid to node vid, or nil if there is none.
+func (g *Graph[S, Sym]) Edge(uid, vid int64) graph.Edge {
+	e, ok := g.edges[[2]int64{uid, vid}]
+	if !ok {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,82 @@
+package fsmgraph
+
+import (
+	"slices"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/topo"
+	"gonum.org/v1/gonum/graph/traverse"
+
+	"github.com/bohdan-natsevych/fsm-generator/examples/mod3"
+	"github.com/bohdan-natsevych/fsm-generator/pkg/fsm"
+)
+
+func states(nodes []graph.Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.(Node[string]).State
+	}
+	return out
+}
+
+func TestTopologicalSort(t *testing.T) {
+	b := fsm.NewBuilder[string, rune]()
+	b.SetInitial("start")
+	b.AddState("done", true)
+	b.On("start", 'a', "mid").On("start", 'b', "mid").On("mid", 'c', "done").On("start", 'd', "done")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	g := New(m)
+	sorted, err := topo.Sort(g)
+	if err != nil {
+		t.Fatalf("unexpected sort error: %v", err)
+	}
+	if got, want := states(sorted), []string{"start", "mid", "done"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	start, _ := g.NodeFor("start")
+	mid, _ := g.NodeFor("mid")
+	e, ok := g.Edge(start.ID(), mid.ID()).(Edge[string, rune])
+	if !ok || !slices.Equal(e.Symbols, []rune{'a', 'b'}) {
+		t.Fatalf("expected one edge labeled [a b], got %#v", g.Edge(start.ID(), mid.ID()))
+	}
+	if g.HasEdgeFromTo(mid.ID(), start.ID()) || !g.HasEdgeBetween(mid.ID(), start.ID()) {
+		t.Fatalf("unexpected edge direction handling")
+	}
+	if g.From(start.ID()).Len() != 2 || g.To(start.ID()).Len() != 0 {
+		t.Fatalf("unexpected neighbour counts")
+	}
+}
+
+func TestBreadthFirstOnMod3(t *testing.T) {
+	m, err := mod3.BuildRunes()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	g := New(m)
+	if g.Nodes().Len() != 3 {
+		t.Fatalf("expected 3 nodes, got %d", g.Nodes().Len())
+	}
+	s0, _ := g.NodeFor("S0")
+	if s0.ID() != 0 {
+		t.Fatalf("expected initial state to have id 0, got %d", s0.ID())
+	}
+	depths := make(map[string]int)
+	var bfs traverse.BreadthFirst
+	bfs.Walk(g, s0, func(n graph.Node, depth int) bool {
+		depths[n.(Node[string]).State] = depth
+		return false
+	})
+	for s, want := range map[string]int{"S0": 0, "S1": 1, "S2": 2} {
+		if depths[s] != want {
+			t.Fatalf("expected %s at depth %d, got %v", s, want, depths)
+		}
+	}
+	if _, err := topo.Sort(g); err == nil {
+		t.Fatalf("expected mod3 to be cyclic")
+	}
+}
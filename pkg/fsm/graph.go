@@ -0,0 +1,531 @@
+package fsm
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Structural analyses of the transition graph, ignoring symbols.
+
+// edgesFrom returns the distinct successors of s in deterministic symbol order.
+func (m *Machine[S, Sym]) edgesFrom(s S, syms []Sym) []S {
+	var out []S
+	seen := make(map[S]struct{})
+	for _, sym := range syms {
+		if to, ok := m.GetTransition(s, sym); ok {
+			if _, dup := seen[to]; !dup {
+				seen[to] = struct{}{}
+				out = append(out, to)
+			}
+		}
+	}
+	return out
+}
+
+// tarjan computes the strongly connected components of the graph induced on
+// nodes by succ, in reverse topological order (components without outgoing
+// edges first). Members of each component are sorted.
+func tarjan[S comparable](nodes []S, succ func(S) []S) [][]S {
+	inGraph := make(map[S]struct{}, len(nodes))
+	for _, s := range nodes {
+		inGraph[s] = struct{}{}
+	}
+	index := make(map[S]int, len(nodes))
+	low := make(map[S]int, len(nodes))
+	onStack := make(map[S]bool, len(nodes))
+	var stack []S
+	var out [][]S
+
+	var strongConnect func(v S)
+	strongConnect = func(v S) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range succ(v) {
+			if _, ok := inGraph[w]; !ok {
+				continue
+			}
+			if _, visited := index[w]; !visited {
+				strongConnect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] == index[v] {
+			comp := make(map[S]struct{})
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp[w] = struct{}{}
+				if w == v {
+					break
+				}
+			}
+			out = append(out, sortedKeys(comp))
+		}
+	}
+	for _, s := range nodes {
+		if _, visited := index[s]; !visited {
+			strongConnect(s)
+		}
+	}
+	return out
+}
+
+// SCCs returns the strongly connected components of the transition graph in
+// reverse topological order: a component is listed before every component that
+// has an edge into it. States are sorted within each component, and the result
+// is deterministic.
+func (m *Machine[S, Sym]) SCCs() [][]S {
+	syms := m.sortedSymbols()
+	states := make(map[S]struct{})
+	for _, s := range m.States() {
+		states[s] = struct{}{}
+	}
+	return tarjan(sortedKeys(states), func(s S) []S { return m.edgesFrom(s, syms) })
+}
+
+// InRecurrentClass reports whether state lies on a cycle that can never be
+// left: its component contains at least one transition and no transition
+// leaves the component. States outside such a class are transient.
+func (m *Machine[S, Sym]) InRecurrentClass(state S) bool {
+	syms := m.sortedSymbols()
+	for _, comp := range m.SCCs() {
+		members := make(map[S]struct{}, len(comp))
+		for _, s := range comp {
+			members[s] = struct{}{}
+		}
+		if _, ok := members[state]; !ok {
+			continue
+		}
+		cyclic := false
+		for _, s := range comp {
+			for _, to := range m.edgesFrom(s, syms) {
+				if _, ok := members[to]; !ok {
+					return false
+				}
+				cyclic = true
+			}
+		}
+		return cyclic
+	}
+	return false
+}
+
+// Cycles enumerates the elementary cycles reachable from the initial state.
+// Each cycle is a state sequence s1..sk with an edge from each state to the
+// next and from sk back to s1; it starts at its smallest state under the
+// package's deterministic order, and a self-loop is a cycle of length one.
+// Cycles differing only in the symbols used are reported once.
+//
+// At most limit cycles are returned; if more exist, ErrCyclesTruncated is
+// returned together with the first limit cycles. A limit of zero or less
+// means no limit.
+func (m *Machine[S, Sym]) Cycles(limit int) ([][]S, error) {
+	syms := m.sortedSymbols()
+	reached := sortedKeys(m.reachable(m.initialState))
+	succ := func(s S) []S { return m.edgesFrom(s, syms) }
+
+	compOf := make(map[S]int, len(reached))
+	for i, comp := range tarjan(reached, succ) {
+		for _, s := range comp {
+			compOf[s] = i
+		}
+	}
+	rank := make(map[S]int, len(reached))
+	for i, s := range reached {
+		rank[s] = i
+	}
+
+	// Johnson's algorithm: a state stays blocked until a cycle is found
+	// through it, which also unblocks the states waiting on it in blockedBy.
+	// Dead ends are thus explored once per start state, and the work between
+	// two cycles found is linear in the size of the machine.
+	var out [][]S
+	var path []S
+	blocked := make(map[S]bool)
+	blockedBy := make(map[S]map[S]struct{})
+	var unblock func(s S)
+	unblock = func(s S) {
+		blocked[s] = false
+		for w := range blockedBy[s] {
+			delete(blockedBy[s], w)
+			if blocked[w] {
+				unblock(w)
+			}
+		}
+	}
+	// circuit extends path from cur looking for edges back to start, visiting
+	// only states ranked at or above start within its component. It reports
+	// whether a cycle was found through cur.
+	var circuit func(start, cur S) (bool, error)
+	circuit = func(start, cur S) (bool, error) {
+		found := false
+		path = append(path, cur)
+		blocked[cur] = true
+		next := succ(cur)
+		for _, to := range next {
+			if compOf[to] != compOf[start] || rank[to] < rank[start] {
+				continue
+			}
+			if to == start {
+				if limit > 0 && len(out) == limit {
+					return found, ErrCyclesTruncated
+				}
+				out = append(out, append([]S(nil), path...))
+				found = true
+				continue
+			}
+			if blocked[to] {
+				continue
+			}
+			ok, err := circuit(start, to)
+			if err != nil {
+				return found, err
+			}
+			found = found || ok
+		}
+		if found {
+			unblock(cur)
+		} else {
+			for _, to := range next {
+				if compOf[to] != compOf[start] || rank[to] < rank[start] {
+					continue
+				}
+				if blockedBy[to] == nil {
+					blockedBy[to] = make(map[S]struct{})
+				}
+				blockedBy[to][cur] = struct{}{}
+			}
+		}
+		path = path[:len(path)-1]
+		return found, nil
+	}
+	for _, s := range reached {
+		clear(blocked)
+		clear(blockedBy)
+		if _, err := circuit(s, s); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// compareTransitions orders transitions by source state, then symbol.
+func compareTransitions[S comparable, Sym comparable](a, b Transition[S, Sym]) int {
+	if c := compareValues(a.From, b.From); c != 0 {
+		return c
+	}
+	return compareValues(a.Symbol, b.Symbol)
+}
+
+// DeadTransitions returns the transitions that can never occur on an accepting
+// run: their source is unreachable from the initial state or their target
+// cannot reach an accepting state. They are sorted by source state, then symbol.
+func (m *Machine[S, Sym]) DeadTransitions() []Transition[S, Sym] {
+	reached := m.reachable(m.initialState)
+	co := m.coreachable()
+	var out []Transition[S, Sym]
+	for t := range m.Transitions() {
+		_, fromOK := reached[t.From]
+		_, toOK := co[t.To]
+		if !fromOK || !toOK {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// walk runs a breadth-first traversal from start, reporting each dequeued state
+// to visitState and each of its transitions, in symbol order, to visitEdge.
+// Either callback may be nil; the traversal stops as soon as one returns false.
+func (m *Machine[S, Sym]) walk(start S, visitState func(S) bool, visitEdge func(S, Sym, S) bool) {
+	syms := m.sortedSymbols()
+	seen := map[S]struct{}{start: {}}
+	queue := []S{start}
+	for i := 0; i < len(queue); i++ {
+		cur := queue[i]
+		if visitState != nil && !visitState(cur) {
+			return
+		}
+		for _, sym := range syms {
+			to, ok := m.GetTransition(cur, sym)
+			if !ok {
+				continue
+			}
+			if visitEdge != nil && !visitEdge(cur, sym, to) {
+				return
+			}
+			if _, ok := seen[to]; !ok {
+				seen[to] = struct{}{}
+				queue = append(queue, to)
+			}
+		}
+	}
+}
+
+// Walk visits the transitions reachable from the initial state in breadth-first
+// order, the transitions of each state in symbol order. It stops as soon as fn
+// returns false. Transitions of unreachable states are never visited.
+func (m *Machine[S, Sym]) Walk(fn func(from S, sym Sym, to S) bool) {
+	m.walk(m.initialState, nil, fn)
+}
+
+// WalkFrom is like Walk but starts from the given state.
+func (m *Machine[S, Sym]) WalkFrom(start S, fn func(from S, sym Sym, to S) bool) {
+	m.walk(start, nil, fn)
+}
+
+// WalkStates visits the states reachable from the initial state, the initial
+// state first, in the same breadth-first order as Walk. It stops as soon as fn
+// returns false.
+func (m *Machine[S, Sym]) WalkStates(fn func(S) bool) {
+	m.walk(m.initialState, fn, nil)
+}
+
+// WalkStatesFrom is like WalkStates but starts from the given state.
+func (m *Machine[S, Sym]) WalkStatesFrom(start S, fn func(S) bool) {
+	m.walk(start, fn, nil)
+}
+
+// BFSOrder returns the states reachable from the initial state in breadth-first
+// order, starting with the initial state. Ties are broken by symbol order: the
+// successors of a state are discovered in the order of its sorted symbols.
+func (m *Machine[S, Sym]) BFSOrder() []S {
+	var out []S
+	m.WalkStates(func(s S) bool {
+		out = append(out, s)
+		return true
+	})
+	return out
+}
+
+// BFSTree returns, for every state reachable from the initial state other than
+// the initial state itself, the transition through which BFSOrder first
+// discovered it. Following the From fields back to the initial state yields a
+// shortest input reaching the state.
+func (m *Machine[S, Sym]) BFSTree() map[S]Transition[S, Sym] {
+	tree := make(map[S]Transition[S, Sym])
+	m.Walk(func(from S, sym Sym, to S) bool {
+		if _, ok := tree[to]; !ok && to != m.initialState {
+			tree[to] = Transition[S, Sym]{From: from, Symbol: sym, To: to}
+		}
+		return true
+	})
+	return tree
+}
+
+// dfs runs a depth-first traversal from the initial state, following symbols in
+// sorted order, and returns the reachable states in pre-order and post-order.
+func (m *Machine[S, Sym]) dfs() (pre, post []S) {
+	syms := m.sortedSymbols()
+	seen := make(map[S]struct{})
+	var visit func(s S)
+	visit = func(s S) {
+		seen[s] = struct{}{}
+		pre = append(pre, s)
+		for _, sym := range syms {
+			if to, ok := m.GetTransition(s, sym); ok {
+				if _, done := seen[to]; !done {
+					visit(to)
+				}
+			}
+		}
+		post = append(post, s)
+	}
+	visit(m.initialState)
+	return pre, post
+}
+
+// DFSOrder returns the states reachable from the initial state in depth-first
+// pre-order, starting with the initial state and following symbols in sorted
+// order.
+func (m *Machine[S, Sym]) DFSOrder() []S {
+	pre, _ := m.dfs()
+	return pre
+}
+
+// DFSPostOrder returns the states reachable from the initial state in the order
+// a depth-first search, following symbols in sorted order, finishes them. The
+// initial state comes last.
+func (m *Machine[S, Sym]) DFSPostOrder() []S {
+	_, post := m.dfs()
+	return post
+}
+
+// ReachableFrom returns the states reachable from state, state included, in
+// the breadth-first order of WalkStatesFrom. An unknown state yields an empty
+// slice and an *UnknownStateError.
+func (m *Machine[S, Sym]) ReachableFrom(state S) ([]S, error) {
+	if !m.hasState(state) {
+		return []S{}, &UnknownStateError{State: state}
+	}
+	var out []S
+	m.WalkStatesFrom(state, func(s S) bool {
+		out = append(out, s)
+		return true
+	})
+	return out, nil
+}
+
+// Reachable reports whether some input leads from state from to state to.
+// Every known state is reachable from itself; unknown states are unreachable.
+func (m *Machine[S, Sym]) Reachable(from, to S) bool {
+	if !m.hasState(from) {
+		return false
+	}
+	found := false
+	m.WalkStatesFrom(from, func(s S) bool {
+		found = s == to
+		return !found
+	})
+	return found
+}
+
+// TerminalStates returns, in deterministic order, the states without outgoing
+// transitions, including states that only appear as transition targets.
+func (m *Machine[S, Sym]) TerminalStates() []S {
+	sources := make(map[S]struct{})
+	for key := range m.transitions {
+		sources[key.From] = struct{}{}
+	}
+	terminal := make(map[S]struct{})
+	for _, s := range m.States() {
+		if _, ok := sources[s]; !ok {
+			terminal[s] = struct{}{}
+		}
+	}
+	return sortedKeys(terminal)
+}
+
+// IsSink reports whether state is a sink: a known, non-accepting state with a
+// transition on every symbol of the alphabet, each leading back to itself.
+// Once entered, a sink is never left and the input is rejected.
+//
+// Every reachable sink is also dead (see DeadStates), but a dead state need not
+// be a sink: it may move between several non-accepting states, or lack
+// transitions and reject by getting stuck. A terminal state (see
+// TerminalStates) has no outgoing transitions at all, so it is never a sink;
+// in particular a machine with an empty alphabet has no sinks.
+func (m *Machine[S, Sym]) IsSink(state S) bool {
+	if len(m.symbols) == 0 || m.Accepting(state) || !m.hasState(state) {
+		return false
+	}
+	for sym := range m.symbols {
+		if to, ok := m.GetTransition(state, sym); !ok || to != state {
+			return false
+		}
+	}
+	return true
+}
+
+// SinkStates returns, in deterministic order, the states for which IsSink
+// holds. Unlike DeadStates it includes unreachable sinks.
+func (m *Machine[S, Sym]) SinkStates() []S {
+	sinks := make(map[S]struct{})
+	for _, s := range m.States() {
+		if m.IsSink(s) {
+			sinks[s] = struct{}{}
+		}
+	}
+	return sortedKeys(sinks)
+}
+
+// AdjacencyMatrix returns the transition graph as a boolean matrix indexed by
+// the positions of states in order: entry [i][j] is true when some symbol
+// leads from order[i] to order[j]. The order must list every state of the
+// machine exactly once; unknown, missing or repeated states yield an error.
+func (m *Machine[S, Sym]) AdjacencyMatrix(order []S) ([][]bool, error) {
+	index := make(map[S]int, len(order))
+	for i, s := range order {
+		if !m.hasState(s) {
+			return nil, &UnknownStateError{State: s}
+		}
+		if _, dup := index[s]; dup {
+			return nil, fmt.Errorf("state %v listed more than once", s)
+		}
+		index[s] = i
+	}
+	for _, s := range m.States() {
+		if _, ok := index[s]; !ok {
+			return nil, fmt.Errorf("state %v missing from order", s)
+		}
+	}
+	matrix := make([][]bool, len(order))
+	for i := range matrix {
+		matrix[i] = make([]bool, len(order))
+	}
+	for key, to := range m.transitions {
+		matrix[index[key.From]][index[to]] = true
+	}
+	return matrix, nil
+}
+
+// EdgeList returns every transition, ordered by source state and then symbol.
+func (m *Machine[S, Sym]) EdgeList() []Transition[S, Sym] {
+	return slices.Collect(m.Transitions())
+}
+
+// shortestPath returns the lexicographically smallest among the shortest inputs
+// leading from start to a state satisfying goal, or false if there is none.
+// It returns an empty path when start itself satisfies goal.
+func (m *Machine[S, Sym]) shortestPath(start S, goal func(S) bool) ([]Sym, bool) {
+	type edge struct {
+		from S
+		sym  Sym
+	}
+	syms := m.sortedSymbols()
+	parent := make(map[S]edge)
+	seen := map[S]struct{}{start: {}}
+	queue := []S{start}
+	for i := 0; i < len(queue); i++ {
+		cur := queue[i]
+		if goal(cur) {
+			path := []Sym{}
+			for s := cur; s != start; s = parent[s].from {
+				path = append(path, parent[s].sym)
+			}
+			slices.Reverse(path)
+			return path, true
+		}
+		for _, sym := range syms {
+			to, ok := m.GetTransition(cur, sym)
+			if !ok {
+				continue
+			}
+			if _, ok := seen[to]; !ok {
+				seen[to] = struct{}{}
+				parent[to] = edge{from: cur, sym: sym}
+				queue = append(queue, to)
+			}
+		}
+	}
+	return nil, false
+}
+
+// PathBetween returns a shortest input leading from state from to state to, or
+// false if to is unreachable. Among equal-length inputs the lexicographically
+// smallest under the package's deterministic symbol order is chosen. The path
+// from a state to itself is empty. Unknown states yield an *UnknownStateError.
+func (m *Machine[S, Sym]) PathBetween(from, to S) ([]Sym, bool, error) {
+	for _, s := range []S{from, to} {
+		if !m.hasState(s) {
+			return nil, false, &UnknownStateError{State: s}
+		}
+	}
+	path, ok := m.shortestPath(from, func(s S) bool { return s == to })
+	return path, ok, nil
+}
+
+// PathToAccepting is like PathBetween but targets the nearest accepting state.
+// The path is empty if from is itself accepting.
+func (m *Machine[S, Sym]) PathToAccepting(from S) ([]Sym, bool, error) {
+	if !m.hasState(from) {
+		return nil, false, &UnknownStateError{State: from}
+	}
+	path, ok := m.shortestPath(from, m.Accepting)
+	return path, ok, nil
+}
@@ -0,0 +1,474 @@
+package fsm
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// buildSCCFixture has a transient chain feeding two cycles, one of which is
+// closed, plus a terminal state.
+func buildSCCFixture(t *testing.T) *Machine[string, rune] {
+	t.Helper()
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'x', "B").On("B", 'x', "C").On("C", 'x', "B")
+	b.On("C", 'y', "D").On("D", 'x', "E").On("E", 'x', "D")
+	b.On("A", 'y', "End")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	return m
+}
+
+func TestSCCsReverseTopological(t *testing.T) {
+	m := buildSCCFixture(t)
+	for i := 0; i < 10; i++ {
+		got := m.SCCs()
+		want := [][]string{{"D", "E"}, {"B", "C"}, {"End"}, {"A"}}
+		if !slices.EqualFunc(got, want, slices.Equal[[]string]) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestInRecurrentClass(t *testing.T) {
+	m := buildSCCFixture(t)
+	for s, want := range map[string]bool{"A": false, "B": false, "C": false, "D": true, "E": true, "End": false, "Unknown": false} {
+		if got := m.InRecurrentClass(s); got != want {
+			t.Fatalf("%s: expected %v, got %v", s, want, got)
+		}
+	}
+}
+
+func TestCyclesEnumeration(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'x', "B").On("B", 'x', "C").On("C", 'x', "A")
+	b.On("B", 'y', "A").On("B", 'z', "A") // two symbols, one cycle
+	b.On("C", 'y', "C")
+	b.On("U", 'x', "U") // unreachable self-loop
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		got, err := m.Cycles(0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := [][]string{{"A", "B", "C"}, {"A", "B"}, {"C"}}
+		if !slices.EqualFunc(got, want, slices.Equal[[]string]) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestCyclesLimit(t *testing.T) {
+	m := buildMod3(t)
+	all, err := m.Cycles(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Self-loops on S0 and S2, plus S0<->S1 and S1<->S2.
+	if len(all) != 4 {
+		t.Fatalf("expected 4 elementary cycles in mod3, got %v", all)
+	}
+	got, err := m.Cycles(2)
+	if !errors.Is(err, ErrCyclesTruncated) {
+		t.Fatalf("expected ErrCyclesTruncated, got %v", err)
+	}
+	if !slices.EqualFunc(got, all[:2], slices.Equal[[]string]) {
+		t.Fatalf("expected first two cycles %v, got %v", all[:2], got)
+	}
+	if _, err := m.Cycles(4); err != nil {
+		t.Fatalf("limit equal to the cycle count must not truncate: %v", err)
+	}
+}
+
+func TestCyclesSkipsDeadEndsOnce(t *testing.T) {
+	// From 1, symbol 0 enters a chain of diamonds with 2^40 paths whose end
+	// only leads back into the chain's entry, already on the path; symbol 1
+	// closes the cycle 0 1. A search without blocking walks every path first.
+	const diamonds = 40
+	b := NewBuilder[int, int]()
+	b.SetInitial(0).On(0, 0, 1).On(1, 1, 0)
+	entry := 2
+	b.On(1, 0, entry)
+	for i := range diamonds {
+		d := entry + 3*i
+		b.On(d, 0, d+1).On(d, 1, d+2).On(d+1, 0, d+3).On(d+2, 0, d+3)
+	}
+	b.On(entry+3*diamonds, 0, 1)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	got, err := m.Cycles(1)
+	if !errors.Is(err, ErrCyclesTruncated) {
+		t.Fatalf("expected ErrCyclesTruncated, got %v", err)
+	}
+	if want := [][]int{{0, 1}}; !slices.EqualFunc(got, want, slices.Equal[[]int]) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDeadTransitions(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'a', "B").On("B", 'b', "A")
+	b.On("A", 'x', "Trap").On("Trap", 'x', "Trap")
+	b.On("U", 'a', "B")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	got := m.DeadTransitions()
+	want := []Transition[string, rune]{
+		{From: "A", Symbol: 'x', To: "Trap"},
+		{From: "Trap", Symbol: 'x', To: "Trap"},
+		{From: "U", Symbol: 'a', To: "B"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if dead := buildMod3(t).DeadTransitions(); len(dead) != 0 {
+		t.Fatalf("expected no dead transitions in mod3, got %v", dead)
+	}
+}
+
+func TestWalkBreadthFirst(t *testing.T) {
+	m := buildSCCFixture(t)
+	var got []Transition[string, rune]
+	m.Walk(func(from string, sym rune, to string) bool {
+		got = append(got, Transition[string, rune]{From: from, Symbol: sym, To: to})
+		return true
+	})
+	want := []Transition[string, rune]{
+		{From: "A", Symbol: 'x', To: "B"},
+		{From: "A", Symbol: 'y', To: "End"},
+		{From: "B", Symbol: 'x', To: "C"},
+		{From: "C", Symbol: 'x', To: "B"},
+		{From: "C", Symbol: 'y', To: "D"},
+		{From: "D", Symbol: 'x', To: "E"},
+		{From: "E", Symbol: 'x', To: "D"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	var states []string
+	m.WalkStates(func(s string) bool {
+		states = append(states, s)
+		return true
+	})
+	if want := []string{"A", "B", "End", "C", "D", "E"}; !slices.Equal(states, want) {
+		t.Fatalf("expected states %v, got %v", want, states)
+	}
+}
+
+func TestWalkEarlyStopAndStart(t *testing.T) {
+	m := buildSCCFixture(t)
+	count := 0
+	m.Walk(func(string, rune, string) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Fatalf("expected walk to stop after 3 transitions, got %d", count)
+	}
+
+	var states []string
+	m.WalkStatesFrom("D", func(s string) bool {
+		states = append(states, s)
+		return true
+	})
+	if want := []string{"D", "E"}; !slices.Equal(states, want) {
+		t.Fatalf("expected states %v, got %v", want, states)
+	}
+
+	var froms []string
+	m.WalkFrom("C", func(from string, _ rune, _ string) bool {
+		froms = append(froms, from)
+		return from != "D"
+	})
+	if want := []string{"C", "C", "B", "D"}; !slices.Equal(froms, want) {
+		t.Fatalf("expected sources %v, got %v", want, froms)
+	}
+}
+
+func TestWalkSkipsUnreachable(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'x', "B").On("U", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	m.Walk(func(from string, _ rune, _ string) bool {
+		if from == "U" {
+			t.Fatalf("visited transition of unreachable state")
+		}
+		return true
+	})
+}
+
+func TestTraversalOrdersMod3(t *testing.T) {
+	m := buildMod3(t)
+	if got, want := m.BFSOrder(), []string{"S0", "S1", "S2"}; !slices.Equal(got, want) {
+		t.Fatalf("BFSOrder: expected %v, got %v", want, got)
+	}
+	if got, want := m.DFSOrder(), []string{"S0", "S1", "S2"}; !slices.Equal(got, want) {
+		t.Fatalf("DFSOrder: expected %v, got %v", want, got)
+	}
+	if got, want := m.DFSPostOrder(), []string{"S2", "S1", "S0"}; !slices.Equal(got, want) {
+		t.Fatalf("DFSPostOrder: expected %v, got %v", want, got)
+	}
+	tree := m.BFSTree()
+	want := map[string]Transition[string, rune]{
+		"S1": {From: "S0", Symbol: '1', To: "S1"},
+		"S2": {From: "S1", Symbol: '0', To: "S2"},
+	}
+	if len(tree) != len(want) {
+		t.Fatalf("BFSTree: expected %v, got %v", want, tree)
+	}
+	for s, tr := range want {
+		if tree[s] != tr {
+			t.Fatalf("BFSTree[%s]: expected %v, got %v", s, tr, tree[s])
+		}
+	}
+}
+
+func TestTraversalOrdersDiffer(t *testing.T) {
+	m := buildSCCFixture(t)
+	if got, want := m.BFSOrder(), []string{"A", "B", "End", "C", "D", "E"}; !slices.Equal(got, want) {
+		t.Fatalf("BFSOrder: expected %v, got %v", want, got)
+	}
+	if got, want := m.DFSOrder(), []string{"A", "B", "C", "D", "E", "End"}; !slices.Equal(got, want) {
+		t.Fatalf("DFSOrder: expected %v, got %v", want, got)
+	}
+	if got, want := m.DFSPostOrder(), []string{"E", "D", "C", "B", "End", "A"}; !slices.Equal(got, want) {
+		t.Fatalf("DFSPostOrder: expected %v, got %v", want, got)
+	}
+	if tr := m.BFSTree()["D"]; tr.From != "C" || tr.Symbol != 'y' {
+		t.Fatalf("BFSTree[D]: expected C --y--> D, got %v", tr)
+	}
+}
+
+func TestReachableFrom(t *testing.T) {
+	m := buildSCCFixture(t)
+	got, err := m.ReachableFrom("C")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"C", "B", "D", "E"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	got, err = m.ReachableFrom("End")
+	if err != nil || !slices.Equal(got, []string{"End"}) {
+		t.Fatalf("expected [End], got %v (err %v)", got, err)
+	}
+
+	got, err = m.ReachableFrom("Unknown")
+	var unknown *UnknownStateError
+	if !errors.As(err, &unknown) || unknown.State != "Unknown" {
+		t.Fatalf("expected UnknownStateError, got %v", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Fatalf("expected empty slice for unknown state, got %#v", got)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	m := buildSCCFixture(t)
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{"A", "E", true},
+		{"D", "B", false},
+		{"E", "D", true},
+		{"End", "End", true},
+		{"Unknown", "Unknown", false},
+		{"A", "Unknown", false},
+	}
+	for _, tc := range cases {
+		if got := m.Reachable(tc.from, tc.to); got != tc.want {
+			t.Fatalf("Reachable(%s, %s): expected %v, got %v", tc.from, tc.to, tc.want, got)
+		}
+	}
+}
+
+func TestTerminalStates(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("Done", true)
+	b.On("A", 'x', "B").On("B", 'x', "A").On("B", 'y', "Done").On("A", 'y', "TargetOnly")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, want := m.TerminalStates(), []string{"Done", "TargetOnly"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := buildMod3(t).TerminalStates(); len(got) != 0 {
+		t.Fatalf("expected no terminal states in mod3, got %v", got)
+	}
+}
+
+func TestSinkStates(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("Ok", true)
+	b.On("A", 'a', "Ok").On("A", 'b', "Sink")
+	b.On("Sink", 'a', "Sink").On("Sink", 'b', "Sink")
+	// Ping and Pong are dead but move between each other, so neither is a sink.
+	b.On("Ok", 'b', "Ping").On("Ping", 'a', "Pong").On("Pong", 'a', "Ping")
+	// Partial self-loops do not absorb every symbol.
+	b.On("Ok", 'a', "Half").On("Half", 'a', "Half")
+	// An unreachable sink is a sink but not reported as dead.
+	b.On("Lost", 'a', "Lost").On("Lost", 'b', "Lost")
+	// Accepting self-loops are never sinks.
+	b.AddState("Loop", true)
+	b.On("Loop", 'a', "Loop").On("Loop", 'b', "Loop")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, want := m.SinkStates(), []string{"Lost", "Sink"}; !slices.Equal(got, want) {
+		t.Fatalf("expected sinks %v, got %v", want, got)
+	}
+	if got, want := m.DeadStates(), []string{"Half", "Ping", "Pong", "Sink"}; !slices.Equal(got, want) {
+		t.Fatalf("expected dead states %v, got %v", want, got)
+	}
+	for s, want := range map[string]bool{"Sink": true, "Lost": true, "Ping": false, "Half": false, "Loop": false, "Ok": false, "Unknown": false} {
+		if got := m.IsSink(s); got != want {
+			t.Fatalf("IsSink(%s): expected %v, got %v", s, want, got)
+		}
+	}
+}
+
+func TestIsSinkEmptyAlphabet(t *testing.T) {
+	// The builder requires a symbol, so construct the machine directly.
+	m := newMachine("A", map[string]struct{}{"A": {}}, map[string]struct{}{}, map[rune]struct{}{}, map[TransitionKey[string, rune]]string{})
+	if m.IsSink("A") || len(m.SinkStates()) != 0 {
+		t.Fatalf("expected no sinks without an alphabet, got %v", m.SinkStates())
+	}
+}
+
+func TestAdjacencyMatrix(t *testing.T) {
+	m := buildMod3(t)
+	got, err := m.AdjacencyMatrix([]string{"S2", "S1", "S0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]bool{
+		{true, true, false},
+		{true, false, true},
+		{false, true, true},
+	}
+	if !slices.EqualFunc(got, want, slices.Equal[[]bool]) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	for _, order := range [][]string{
+		{"S0", "S1"},
+		{"S0", "S1", "S2", "S3"},
+		{"S0", "S1", "S1", "S2"},
+	} {
+		if _, err := m.AdjacencyMatrix(order); err == nil {
+			t.Fatalf("expected error for order %v", order)
+		}
+	}
+}
+
+// graphNode and graphEdge mirror the int64-identified nodes and edges that
+// gonum's graph/simple package consumes.
+type graphNode struct{ id int64 }
+type graphEdge struct{ from, to graphNode }
+
+func TestEdgeListToGraphEdges(t *testing.T) {
+	m := buildSCCFixture(t)
+	ids := make(map[string]int64)
+	for i, s := range m.BFSOrder() {
+		ids[s] = int64(i)
+	}
+	var edges []graphEdge
+	seen := make(map[graphEdge]bool)
+	for _, tr := range m.EdgeList() {
+		e := graphEdge{graphNode{ids[tr.From]}, graphNode{ids[tr.To]}}
+		if !seen[e] { // simple graphs allow one edge per ordered node pair
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+	want := []graphEdge{
+		{graphNode{0}, graphNode{1}}, // A -> B
+		{graphNode{0}, graphNode{2}}, // A -> End
+		{graphNode{1}, graphNode{3}}, // B -> C
+		{graphNode{3}, graphNode{1}}, // C -> B
+		{graphNode{3}, graphNode{4}}, // C -> D
+		{graphNode{4}, graphNode{5}}, // D -> E
+		{graphNode{5}, graphNode{4}}, // E -> D
+	}
+	if !slices.Equal(edges, want) {
+		t.Fatalf("expected %v, got %v", want, edges)
+	}
+}
+
+func TestPathBetween(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("Anonymous")
+	b.AddState("Authenticated", true)
+	b.On("Anonymous", 'l', "Authenticated")
+	b.On("Authenticated", 'f', "Warned").On("Authenticated", 'b', "Warned")
+	b.On("Warned", 'f', "Locked").On("Authenticated", 'o', "Anonymous")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	cases := []struct {
+		from, to string
+		want     string
+		ok       bool
+	}{
+		{"Authenticated", "Locked", "bf", true},
+		{"Anonymous", "Locked", "lbf", true},
+		{"Warned", "Warned", "", true},
+		{"Locked", "Anonymous", "", false},
+	}
+	for _, tc := range cases {
+		got, ok, err := m.PathBetween(tc.from, tc.to)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok != tc.ok || string(got) != tc.want {
+			t.Fatalf("PathBetween(%s, %s): expected %q %v, got %q %v", tc.from, tc.to, tc.want, tc.ok, string(got), ok)
+		}
+		if tc.ok && got == nil {
+			t.Fatalf("expected non-nil path for reachable target")
+		}
+	}
+	if _, _, err := m.PathBetween("Anonymous", "Nowhere"); err == nil {
+		t.Fatalf("expected error for unknown target")
+	}
+
+	got, ok, err := m.PathToAccepting("Warned")
+	if err != nil || ok {
+		t.Fatalf("expected no accepting path from Warned, got %q %v %v", string(got), ok, err)
+	}
+	got, ok, err = m.PathToAccepting("Anonymous")
+	if err != nil || !ok || string(got) != "l" {
+		t.Fatalf("expected path l, got %q %v %v", string(got), ok, err)
+	}
+	got, ok, _ = m.PathToAccepting("Authenticated")
+	if !ok || got == nil || len(got) != 0 {
+		t.Fatalf("expected empty path from accepting state, got %#v %v", got, ok)
+	}
+	var unknown *UnknownStateError
+	if _, _, err := m.PathToAccepting("Nowhere"); !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownStateError, got %v", err)
+	}
+}
@@ -0,0 +1,484 @@
+package fsm
+
+import (
+	"fmt"
+	"iter"
+	"math/big"
+	"math/rand"
+	"slices"
+)
+
+// Language-level queries over a built Machine.
+
+// successors builds an adjacency index from the flat transition map so that
+// graph traversals run in O(states + transitions).
+func (m *Machine[S, Sym]) successors() map[S][]S {
+	adj := make(map[S][]S)
+	for key, to := range m.transitions {
+		adj[key.From] = append(adj[key.From], to)
+	}
+	return adj
+}
+
+// reachable returns the set of states reachable from start, start included.
+func (m *Machine[S, Sym]) reachable(start S) map[S]struct{} {
+	adj := m.successors()
+	reached := map[S]struct{}{start: {}}
+	queue := []S{start}
+	for i := 0; i < len(queue); i++ {
+		for _, to := range adj[queue[i]] {
+			if _, ok := reached[to]; !ok {
+				reached[to] = struct{}{}
+				queue = append(queue, to)
+			}
+		}
+	}
+	return reached
+}
+
+// coreachable returns the set of states from which some accepting state is reachable.
+func (m *Machine[S, Sym]) coreachable() map[S]struct{} {
+	pred := m.predecessorIndex()
+	reached := make(map[S]struct{}, len(m.accepting))
+	queue := make([]S, 0, len(m.accepting))
+	for s := range m.accepting {
+		reached[s] = struct{}{}
+		queue = append(queue, s)
+	}
+	for i := 0; i < len(queue); i++ {
+		for _, key := range pred[queue[i]] {
+			if _, ok := reached[key.From]; !ok {
+				reached[key.From] = struct{}{}
+				queue = append(queue, key.From)
+			}
+		}
+	}
+	return reached
+}
+
+// splitReachable partitions the states reachable from the initial state into
+// useful ones, from which an accepting state is reachable, and dead ones.
+func (m *Machine[S, Sym]) splitReachable() (useful, dead map[S]struct{}) {
+	co := m.coreachable()
+	useful = make(map[S]struct{})
+	dead = make(map[S]struct{})
+	for s := range m.reachable(m.initialState) {
+		if _, ok := co[s]; ok {
+			useful[s] = struct{}{}
+		} else {
+			dead[s] = struct{}{}
+		}
+	}
+	return useful, dead
+}
+
+// useful returns the states that are both reachable from the initial state and
+// co-reachable to an accepting state.
+func (m *Machine[S, Sym]) useful() map[S]struct{} {
+	useful, _ := m.splitReachable()
+	return useful
+}
+
+// UsefulStates returns, in deterministic order, the states that are reachable
+// from the initial state and from which an accepting state is reachable.
+func (m *Machine[S, Sym]) UsefulStates() []S {
+	return sortedKeys(m.useful())
+}
+
+// DeadStates returns, in deterministic order, the states reachable from the
+// initial state from which no accepting state can be reached. Unreachable
+// states are never reported, even if they are dead as well; Trim removes both
+// kinds.
+func (m *Machine[S, Sym]) DeadStates() []S {
+	_, dead := m.splitReachable()
+	return sortedKeys(dead)
+}
+
+// IsEmpty reports whether the machine accepts no input at all,
+// i.e. no accepting state is reachable from the initial state.
+func (m *Machine[S, Sym]) IsEmpty() bool {
+	for s := range m.reachable(m.initialState) {
+		if m.Accepting(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsUniversal reports whether the machine accepts every input over its alphabet.
+// The transition function must be total on the reachable states; a missing
+// transition is reported as a *TransitionError.
+func (m *Machine[S, Sym]) IsUniversal() (bool, error) {
+	universal := true
+	for s := range m.reachable(m.initialState) {
+		for sym := range m.symbols {
+			if !m.HasTransition(s, sym) {
+				return false, &TransitionError[S, Sym]{From: s, Symbol: sym, Position: -1}
+			}
+		}
+		if !m.Accepting(s) {
+			universal = false
+		}
+	}
+	return universal, nil
+}
+
+// ShortestAccepted returns a shortest input accepted by the machine, or false
+// when the language is empty. Among equal-length witnesses the lexicographically
+// smallest one (under the package's deterministic symbol order) is returned.
+func (m *Machine[S, Sym]) ShortestAccepted() ([]Sym, bool) {
+	return m.shortestPath(m.initialState, m.Accepting)
+}
+
+// FindCompletion evaluates prefix and returns a shortest suffix of at most
+// maxLen symbols that makes the whole input accepted, choosing the smallest
+// such suffix under the package's deterministic symbol order. An accepted
+// prefix yields an empty suffix. A negative maxLen means no bound. If the
+// prefix cannot be evaluated its *TransitionError is returned; if no suffix
+// fits the bound a *NoCompletionError is returned.
+func (m *Machine[S, Sym]) FindCompletion(prefix []Sym, maxLen int) ([]Sym, error) {
+	state, err := m.Eval(prefix)
+	if err != nil {
+		return nil, err
+	}
+	suffix, ok := m.shortestPath(state, m.Accepting)
+	if !ok || (maxLen >= 0 && len(suffix) > maxLen) {
+		return nil, &NoCompletionError{MaxLen: maxLen}
+	}
+	return suffix, nil
+}
+
+// AcceptedStrings enumerates every accepted input of length at most maxLen in
+// length-then-lexicographic order, using the package's deterministic symbol order.
+func (m *Machine[S, Sym]) AcceptedStrings(maxLen int) iter.Seq[[]Sym] {
+	return m.AcceptedStringsOrdered(maxLen, m.sortedSymbols())
+}
+
+// AcceptedStringsOrdered is like AcceptedStrings but orders symbols as they appear
+// in order. Symbols missing from order are never explored.
+//
+// Strings are produced lazily: branches that cannot reach an accepting state in
+// the remaining number of steps are pruned, and enumeration stops as soon as the
+// caller breaks out of the range loop. Each yielded slice is freshly allocated.
+func (m *Machine[S, Sym]) AcceptedStringsOrdered(maxLen int, order []Sym) iter.Seq[[]Sym] {
+	return func(yield func([]Sym) bool) {
+		if maxLen < 0 {
+			return
+		}
+		// live[r] holds the states that reach an accepting state in exactly r steps.
+		live := make([]map[S]struct{}, maxLen+1)
+		live[0] = make(map[S]struct{}, len(m.accepting))
+		for s := range m.accepting {
+			live[0][s] = struct{}{}
+		}
+		for r := 1; r <= maxLen; r++ {
+			live[r] = make(map[S]struct{})
+			for key, to := range m.transitions {
+				if _, ok := live[r-1][to]; ok {
+					live[r][key.From] = struct{}{}
+				}
+			}
+		}
+
+		buf := make([]Sym, 0, maxLen)
+		var walk func(cur S, remaining int) bool
+		walk = func(cur S, remaining int) bool {
+			if _, ok := live[remaining][cur]; !ok {
+				return true
+			}
+			if remaining == 0 {
+				return yield(slices.Clone(buf))
+			}
+			for _, sym := range order {
+				to, ok := m.GetTransition(cur, sym)
+				if !ok {
+					continue
+				}
+				buf = append(buf, sym)
+				cont := walk(to, remaining-1)
+				buf = buf[:len(buf)-1]
+				if !cont {
+					return false
+				}
+			}
+			return true
+		}
+		for n := 0; n <= maxLen; n++ {
+			if !walk(m.initialState, n) {
+				return
+			}
+		}
+	}
+}
+
+// suffixCounts computes, for r = 0..n, the number of inputs of length r that
+// lead from each state to an accepting state. Missing transitions count as
+// rejection. Only the last layer is returned unless keepAll is set.
+func (m *Machine[S, Sym]) suffixCounts(n int, keepAll bool) []map[S]*big.Int {
+	cur := make(map[S]*big.Int, len(m.accepting))
+	for s := range m.accepting {
+		cur[s] = big.NewInt(1)
+	}
+	var layers []map[S]*big.Int
+	if keepAll {
+		layers = make([]map[S]*big.Int, 0, n+1)
+		layers = append(layers, cur)
+	}
+	for r := 1; r <= n; r++ {
+		next := make(map[S]*big.Int, len(cur))
+		for key, to := range m.transitions {
+			c, ok := cur[to]
+			if !ok {
+				continue
+			}
+			acc, ok := next[key.From]
+			if !ok {
+				acc = new(big.Int)
+				next[key.From] = acc
+			}
+			acc.Add(acc, c)
+		}
+		cur = next
+		if keepAll {
+			layers = append(layers, cur)
+		}
+	}
+	if !keepAll {
+		layers = []map[S]*big.Int{cur}
+	}
+	return layers
+}
+
+// CountAccepted returns the number of accepted inputs of exactly length n.
+func (m *Machine[S, Sym]) CountAccepted(n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("length must be non-negative, got %d", n)
+	}
+	layers := m.suffixCounts(n, false)
+	if c, ok := layers[0][m.initialState]; ok {
+		return new(big.Int).Set(c), nil
+	}
+	return new(big.Int), nil
+}
+
+// SampleAccepted draws an input of exactly the given length uniformly at random
+// from the accepted inputs of that length. It returns a *NoAcceptedStringError
+// when no such input exists.
+func (m *Machine[S, Sym]) SampleAccepted(length int, rng *rand.Rand) ([]Sym, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("length must be non-negative, got %d", length)
+	}
+	layers := m.suffixCounts(length, true)
+	total, ok := layers[length][m.initialState]
+	if !ok || total.Sign() == 0 {
+		return nil, &NoAcceptedStringError{Length: length}
+	}
+
+	syms := m.sortedSymbols()
+	out := make([]Sym, 0, length)
+	cur := m.initialState
+	k := new(big.Int).Rand(rng, total)
+	for r := length; r > 0; r-- {
+		// k indexes the accepted suffixes from cur; find the symbol whose block contains it.
+		for _, sym := range syms {
+			to, ok := m.GetTransition(cur, sym)
+			if !ok {
+				continue
+			}
+			c, ok := layers[r-1][to]
+			if !ok {
+				continue
+			}
+			if k.Cmp(c) < 0 {
+				out = append(out, sym)
+				cur = to
+				break
+			}
+			k.Sub(k, c)
+		}
+	}
+	return out, nil
+}
+
+// IsFiniteLanguage reports whether the machine accepts only finitely many inputs,
+// i.e. no strongly connected component of useful states contains a cycle.
+func (m *Machine[S, Sym]) IsFiniteLanguage() bool {
+	useful := m.useful()
+	syms := m.sortedSymbols()
+	for _, comp := range tarjan(sortedKeys(useful), func(s S) []S { return m.edgesFrom(s, syms) }) {
+		if len(comp) > 1 || slices.Contains(m.edgesFrom(comp[0], syms), comp[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AcceptedLengthBounds returns the lengths of the shortest and longest accepted
+// inputs. When the language is infinite, finite is false and maxLen is -1. An
+// empty language yields ErrEmptyLanguage.
+func (m *Machine[S, Sym]) AcceptedLengthBounds() (minLen, maxLen int, finite bool, err error) {
+	shortest, ok := m.ShortestAccepted()
+	if !ok {
+		return 0, 0, false, ErrEmptyLanguage
+	}
+	useful := m.useful()
+	syms := m.sortedSymbols()
+	succ := func(s S) []S { return m.edgesFrom(s, syms) }
+
+	// Components arrive successors first, so longest is known for every useful
+	// successor by the time a state is processed.
+	longest := make(map[S]int, len(useful))
+	for _, comp := range tarjan(sortedKeys(useful), succ) {
+		s := comp[0]
+		if len(comp) > 1 || slices.Contains(succ(s), s) {
+			return len(shortest), -1, false, nil
+		}
+		best := -1
+		if m.Accepting(s) {
+			best = 0
+		}
+		for _, to := range succ(s) {
+			if _, ok := useful[to]; ok {
+				best = max(best, longest[to]+1)
+			}
+		}
+		longest[s] = best
+	}
+	return len(shortest), longest[m.initialState], true, nil
+}
+
+// LanguageCycle returns a cycle among the useful states (reachable from the
+// initial state and able to reach an accepting state), which is exactly what
+// makes the accepted language infinite. The cycle is reported as a state
+// sequence s1..sk with a transition from each state to the next and from sk
+// back to s1; a self-loop is a sequence of length one. Cycles among useless
+// states are ignored. The result is deterministic.
+func (m *Machine[S, Sym]) LanguageCycle() ([]S, bool) {
+	useful := m.useful()
+	syms := m.sortedSymbols()
+
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := make(map[S]int, len(useful))
+	var stack []S
+	var cycle []S
+	var visit func(s S) bool
+	visit = func(s S) bool {
+		color[s] = grey
+		stack = append(stack, s)
+		for _, sym := range syms {
+			to, ok := m.GetTransition(s, sym)
+			if !ok {
+				continue
+			}
+			if _, ok := useful[to]; !ok {
+				continue
+			}
+			switch color[to] {
+			case grey:
+				i := slices.Index(stack, to)
+				cycle = slices.Clone(stack[i:])
+				return true
+			case white:
+				if visit(to) {
+					return true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[s] = black
+		return false
+	}
+	for _, s := range sortedKeys(useful) {
+		if color[s] == white && visit(s) {
+			return cycle, true
+		}
+	}
+	return nil, false
+}
+
+// ClosestAccepted returns an accepted input at minimal edit distance from input,
+// together with that distance. Edits are insertions, deletions and
+// substitutions of single symbols from the machine's alphabet, each costing
+// one. An accepted input is returned unchanged with distance 0. If no accepted
+// input is within maxEdits edits, or maxEdits is negative, an error is
+// returned. Ties are broken
+// deterministically, preferring to keep input symbols and then the package's
+// symbol order.
+func (m *Machine[S, Sym]) ClosestAccepted(input []Sym, maxEdits int) ([]Sym, int, error) {
+	type node struct {
+		pos   int
+		state S
+	}
+	type step struct {
+		prev node
+		emit bool
+		sym  Sym
+	}
+	if maxEdits < 0 {
+		return nil, 0, fmt.Errorf("negative edit budget %d", maxEdits)
+	}
+	syms := m.sortedSymbols()
+	dist := make(map[node]int)
+	parent := make(map[node]step)
+	buckets := make([][]node, maxEdits+1)
+	relax := func(from, to node, cost int, emit bool, sym Sym) {
+		d := dist[from] + cost
+		if d > maxEdits {
+			return
+		}
+		if old, ok := dist[to]; ok && old <= d {
+			return
+		}
+		dist[to] = d
+		parent[to] = step{prev: from, emit: emit, sym: sym}
+		buckets[d] = append(buckets[d], to)
+	}
+
+	start := node{pos: 0, state: m.initialState}
+	dist[start] = 0
+	buckets[0] = append(buckets[0], start)
+	for d := 0; d <= maxEdits; d++ {
+		for i := 0; i < len(buckets[d]); i++ {
+			cur := buckets[d][i]
+			if dist[cur] != d {
+				continue
+			}
+			if cur.pos == len(input) && m.Accepting(cur.state) {
+				var out []Sym
+				for n := cur; n != start; n = parent[n].prev {
+					if parent[n].emit {
+						out = append(out, parent[n].sym)
+					}
+				}
+				slices.Reverse(out)
+				if out == nil {
+					out = []Sym{}
+				}
+				return out, d, nil
+			}
+			var none Sym
+			if cur.pos < len(input) {
+				in := input[cur.pos]
+				if to, ok := m.GetTransition(cur.state, in); ok {
+					relax(cur, node{cur.pos + 1, to}, 0, true, in)
+				}
+				relax(cur, node{cur.pos + 1, cur.state}, 1, false, none)
+				for _, sym := range syms {
+					if to, ok := m.GetTransition(cur.state, sym); ok && sym != in {
+						relax(cur, node{cur.pos + 1, to}, 1, true, sym)
+					}
+				}
+			}
+			for _, sym := range syms {
+				if to, ok := m.GetTransition(cur.state, sym); ok {
+					relax(cur, node{cur.pos, to}, 1, true, sym)
+				}
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("no accepted input within %d edits", maxEdits)
+}
@@ -0,0 +1,550 @@
+package fsm
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// buildMod3 returns the modulo-3 machine over runes with only S0 accepting.
+func buildMod3(t testing.TB, opts ...Option) *Machine[string, rune] {
+	t.Helper()
+	b := NewBuilder[string, rune](opts...)
+	b.AddState("S0", true).AddState("S1", false).AddState("S2", false)
+	b.SetInitial("S0")
+	b.AddSymbol('0').AddSymbol('1')
+	b.On("S0", '0', "S0").On("S0", '1', "S1")
+	b.On("S1", '0', "S2").On("S1", '1', "S0")
+	b.On("S2", '0', "S1").On("S2", '1', "S2")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	return m
+}
+
+func TestIsEmpty(t *testing.T) {
+	m := buildMod3(t, WithErrorWhenNoAcceptingReachable())
+	if m.IsEmpty() {
+		t.Fatalf("expected mod3 language to be non-empty")
+	}
+
+	// Accepting state exists but is unreachable; only buildable without the option.
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("A", false).AddState("B", true)
+	b.On("A", 'x', "A")
+	m2, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !m2.IsEmpty() {
+		t.Fatalf("expected empty language when no accepting state is reachable")
+	}
+}
+
+func TestIsUniversal(t *testing.T) {
+	m := buildMod3(t, WithErrorWhenNoAcceptingReachable())
+	universal, err := m.IsUniversal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if universal {
+		t.Fatalf("mod3 divisibility machine should not be universal")
+	}
+
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("A", true).AddState("B", true).AddState("Dead", false)
+	b.On("A", 'x', "B").On("A", 'y', "A")
+	b.On("B", 'x', "A").On("B", 'y', "B")
+	// Dead is unreachable, so its missing row does not matter.
+	m2, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	universal, err = m2.IsUniversal()
+	if err != nil || !universal {
+		t.Fatalf("expected universal=true, got %v, err: %v", universal, err)
+	}
+}
+
+func TestIsUniversalRequiresTotality(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("A", true)
+	b.AddSymbol('y')
+	b.On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	universal, err := m.IsUniversal()
+	if universal {
+		t.Fatalf("expected universal=false for partial machine")
+	}
+	var terr *TransitionError[string, rune]
+	if !errors.As(err, &terr) {
+		t.Fatalf("expected *TransitionError, got %v", err)
+	}
+	if terr.From != "A" || terr.Symbol != 'y' {
+		t.Fatalf("unexpected missing transition reported: %v", terr)
+	}
+}
+
+func TestShortestAccepted(t *testing.T) {
+	// Initial state accepting: the empty string is the witness.
+	w, ok := buildMod3(t).ShortestAccepted()
+	if !ok || w == nil || len(w) != 0 {
+		t.Fatalf("expected empty witness, got %q, ok=%v", string(w), ok)
+	}
+
+	b := NewBuilder[string, rune]()
+	b.SetInitial("S0")
+	b.AddState("S2", true)
+	b.On("S0", '0', "S0").On("S0", '1', "S1")
+	b.On("S1", '0', "S2").On("S1", '1', "S0")
+	b.On("S2", '0', "S1").On("S2", '1', "S2")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	w, ok = m.ShortestAccepted()
+	if !ok || string(w) != "10" {
+		t.Fatalf("expected witness \"10\", got %q, ok=%v", string(w), ok)
+	}
+}
+
+func TestShortestAcceptedTieBreak(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		b := NewBuilder[string, rune]()
+		b.SetInitial("A")
+		b.AddState("B", true).AddState("C", true)
+		b.On("A", 'z', "C").On("A", 'b', "B").On("A", 'y', "C")
+		m, err := b.Build()
+		if err != nil {
+			t.Fatalf("unexpected build error: %v", err)
+		}
+		w, ok := m.ShortestAccepted()
+		if !ok || string(w) != "b" {
+			t.Fatalf("expected witness \"b\", got %q, ok=%v", string(w), ok)
+		}
+	}
+}
+
+func TestShortestAcceptedEmptyLanguage(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if w, ok := m.ShortestAccepted(); ok || w != nil {
+		t.Fatalf("expected no witness, got %q, ok=%v", string(w), ok)
+	}
+}
+
+func TestAcceptedStringsMatchesBruteForce(t *testing.T) {
+	m := buildMod3(t)
+	var want []string
+	for n := 0; n <= 5; n++ {
+		for v := 0; v < 1<<n; v++ {
+			w := make([]rune, n)
+			for i := range w {
+				w[i] = '0' + rune(v>>(n-1-i)&1)
+			}
+			if ok, err := m.EvalAccepting(w); err == nil && ok {
+				want = append(want, string(w))
+			}
+		}
+	}
+
+	var got []string
+	for w := range m.AcceptedStrings(5) {
+		got = append(got, string(w))
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("enumeration mismatch:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestAcceptedStringsOrderedAndEarlyBreak(t *testing.T) {
+	m := buildMod3(t)
+	var got []string
+	for w := range m.AcceptedStringsOrdered(3, []rune{'1', '0'}) {
+		got = append(got, string(w))
+		if len(got) == 4 {
+			break
+		}
+	}
+	want := []string{"", "0", "11", "00"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAcceptedStringsInfiniteLanguageLargeBound(t *testing.T) {
+	m := buildMod3(t)
+	count := 0
+	for range m.AcceptedStrings(1000) {
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	if count != 10 {
+		t.Fatalf("expected to stop after 10 strings, got %d", count)
+	}
+}
+
+func TestCountAcceptedBruteForce(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.AddSymbol('c')
+	b.On("A", 'a', "B").On("B", 'a', "A").On("B", 'b', "B")
+	// Missing transitions (A on b, anything on c) count as rejection.
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	alphabet := []rune{'a', 'b', 'c'}
+	for n := 0; n <= 6; n++ {
+		want := 0
+		total := 1
+		for i := 0; i < n; i++ {
+			total *= len(alphabet)
+		}
+		for v := 0; v < total; v++ {
+			w := make([]rune, n)
+			for i, x := 0, v; i < n; i, x = i+1, x/len(alphabet) {
+				w[i] = alphabet[x%len(alphabet)]
+			}
+			if ok, err := m.EvalAccepting(w); err == nil && ok {
+				want++
+			}
+		}
+		got, err := m.CountAccepted(n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Int64() != int64(want) {
+			t.Fatalf("n=%d: expected %d, got %v", n, want, got)
+		}
+	}
+}
+
+func TestCountAcceptedDivisibleByThree(t *testing.T) {
+	m := buildMod3(t)
+	for _, n := range []int{0, 1, 2, 7, 64, 10000} {
+		// Numbers 0..2^n-1 divisible by 3: floor((2^n-1)/3) + 1.
+		want := new(big.Int).Lsh(big.NewInt(1), uint(n))
+		want.Sub(want, big.NewInt(1))
+		want.Div(want, big.NewInt(3))
+		want.Add(want, big.NewInt(1))
+		got, err := m.CountAccepted(n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("n=%d: expected %v, got %v", n, want, got)
+		}
+	}
+	if _, err := m.CountAccepted(-1); err == nil {
+		t.Fatalf("expected error for negative length")
+	}
+}
+
+func TestSampleAcceptedUniform(t *testing.T) {
+	// Accepts strings over {a,b} whose last symbol is 'a'. Of length 3 there are 4:
+	// aaa, aba, baa, bba, and they are reached through states of differing fan-out.
+	b := NewBuilder[string, rune]()
+	b.SetInitial("N")
+	b.AddState("Y", true)
+	b.On("N", 'a', "Y").On("N", 'b', "N")
+	b.On("Y", 'a', "Y").On("Y", 'b', "N")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	const samples = 8000
+	counts := make(map[string]int)
+	for i := 0; i < samples; i++ {
+		w, err := m.SampleAccepted(3, rng)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok, err := m.EvalAccepting(w); err != nil || !ok {
+			t.Fatalf("sampled %q is not accepted", string(w))
+		}
+		counts[string(w)]++
+	}
+	if len(counts) != 4 {
+		t.Fatalf("expected 4 distinct samples, got %v", counts)
+	}
+	for w, c := range counts {
+		if c < samples/4*8/10 || c > samples/4*12/10 {
+			t.Fatalf("sample %q drawn %d times; distribution not uniform: %v", w, c, counts)
+		}
+	}
+}
+
+func TestSampleAcceptedNoString(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'x', "B")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	if w, err := m.SampleAccepted(1, rng); err != nil || string(w) != "x" {
+		t.Fatalf("expected \"x\", got %q, err: %v", string(w), err)
+	}
+	_, err = m.SampleAccepted(2, rng)
+	var nerr *NoAcceptedStringError
+	if !errors.As(err, &nerr) || nerr.Length != 2 {
+		t.Fatalf("expected *NoAcceptedStringError for length 2, got %v", err)
+	}
+}
+
+func TestIsFiniteLanguage(t *testing.T) {
+	// Finite: a then b, with a self-loop only on a dead trap state.
+	b := NewBuilder[string, rune]()
+	b.SetInitial("0")
+	b.AddState("2", true)
+	b.On("0", 'a', "1").On("1", 'b', "2")
+	b.On("0", 'x', "Trap").On("Trap", 'x', "Trap")
+	// Unreachable cycle that could reach acceptance.
+	b.On("U1", 'a', "U2").On("U2", 'a', "U1").On("U2", 'b', "2")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !m.IsFiniteLanguage() {
+		cycle, _ := m.LanguageCycle()
+		t.Fatalf("expected finite language, got cycle %v", cycle)
+	}
+}
+
+func TestLanguageCycleSelfLoop(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'a', "B").On("B", 'b', "B")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if m.IsFiniteLanguage() {
+		t.Fatalf("expected infinite language")
+	}
+	cycle, ok := m.LanguageCycle()
+	if !ok || !slices.Equal(cycle, []string{"B"}) {
+		t.Fatalf("expected self-loop cycle [B], got %v", cycle)
+	}
+}
+
+func TestLanguageCycleMultiState(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("D", true)
+	b.On("A", 'a', "B").On("B", 'b', "C").On("C", 'c', "A").On("C", 'd', "D")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	cycle, ok := m.LanguageCycle()
+	if !ok || !slices.Equal(cycle, []string{"A", "B", "C"}) {
+		t.Fatalf("expected cycle [A B C], got %v", cycle)
+	}
+}
+
+func TestUsefulStates(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'a', "B").On("A", 'x', "Trap").On("U", 'a', "B")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.UsefulStates(); !slices.Equal(got, []string{"A", "B"}) {
+		t.Fatalf("expected [A B], got %v", got)
+	}
+}
+
+func TestDeadStates(t *testing.T) {
+	if got := buildMod3(t).DeadStates(); len(got) != 0 {
+		t.Fatalf("expected no dead states in mod3, got %v", got)
+	}
+
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'a', "B").On("A", 'x', "Trap").On("Trap", 'x', "Trap")
+	b.On("U", 'x', "UTrap") // unreachable and dead: not reported
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m.DeadStates(); !slices.Equal(got, []string{"Trap"}) {
+		t.Fatalf("expected [Trap], got %v", got)
+	}
+}
+
+func TestClosestAccepted(t *testing.T) {
+	// Accepts exactly "abc" and "xyz".
+	b := NewBuilder[string, rune]()
+	b.SetInitial("")
+	for _, w := range []string{"abc", "xyz"} {
+		for i := range w {
+			b.On(w[:i], rune(w[i]), w[:i+1])
+		}
+		b.AddState(w, true)
+	}
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	cases := []struct {
+		in   string
+		want string
+		dist int
+	}{
+		{"abc", "abc", 0},
+		{"abd", "abc", 1},  // substitution
+		{"ac", "abc", 1},   // insertion
+		{"abbc", "abc", 1}, // deletion
+		{"xbz", "xyz", 1},
+		{"", "abc", 3},
+		{"q", "abc", 3},
+	}
+	for _, c := range cases {
+		got, d, err := m.ClosestAccepted([]rune(c.in), 5)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", c.in, err)
+		}
+		if string(got) != c.want || d != c.dist {
+			t.Fatalf("%q: expected %q at distance %d, got %q at %d", c.in, c.want, c.dist, string(got), d)
+		}
+	}
+	// "ay" is two edits from both words; the choice must be stable.
+	first, _, _ := m.ClosestAccepted([]rune("ay"), 5)
+	for i := 0; i < 20; i++ {
+		if got, d, _ := m.ClosestAccepted([]rune("ay"), 5); string(got) != string(first) || d != 2 {
+			t.Fatalf("unstable tie-break: %q vs %q (distance %d)", string(got), string(first), d)
+		}
+	}
+	if _, _, err := m.ClosestAccepted([]rune("q"), 2); err == nil {
+		t.Fatalf("expected error when no accepted input is within the bound")
+	}
+	for _, budget := range []int{-1, -2} {
+		if _, _, err := m.ClosestAccepted([]rune("abc"), budget); err == nil || !strings.Contains(err.Error(), "negative edit budget") {
+			t.Fatalf("expected a negative budget error for %d, got %v", budget, err)
+		}
+	}
+}
+
+func TestClosestAcceptedMod3(t *testing.T) {
+	m := buildMod3(t)
+	got, d, err := m.ClosestAccepted([]rune("1111"), 3)
+	if err != nil || d != 0 || string(got) != "1111" {
+		t.Fatalf("expected accepted input unchanged, got %q at %d, err: %v", string(got), d, err)
+	}
+	got, d, err = m.ClosestAccepted([]rune("111"), 3)
+	if err != nil || d != 1 {
+		t.Fatalf("expected distance 1, got %q at %d, err: %v", string(got), d, err)
+	}
+	if ok, _ := m.EvalAccepting(got); !ok {
+		t.Fatalf("witness %q is not accepted", string(got))
+	}
+}
+
+func TestFindCompletion(t *testing.T) {
+	// Accepts "cat", "car" and "cart"; "x" leads to a trap.
+	b := NewBuilder[string, rune]()
+	b.SetInitial("")
+	for _, w := range []string{"cat", "cart"} {
+		for i := range w {
+			b.On(w[:i], rune(w[i]), w[:i+1])
+		}
+		b.AddState(w, true)
+	}
+	b.AddState("car", true)
+	b.On("", 'x', "trap")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	cases := []struct {
+		prefix string
+		maxLen int
+		want   string
+	}{
+		{"c", 2, "ar"},
+		{"ca", -1, "r"},
+		{"car", 0, ""},
+		{"", 3, "car"},
+	}
+	for _, tc := range cases {
+		got, err := m.FindCompletion([]rune(tc.prefix), tc.maxLen)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.prefix, err)
+		}
+		if got == nil || string(got) != tc.want {
+			t.Fatalf("%q: expected %q, got %q", tc.prefix, tc.want, string(got))
+		}
+	}
+
+	var nc *NoCompletionError
+	if _, err := m.FindCompletion([]rune("c"), 1); !errors.As(err, &nc) || nc.MaxLen != 1 {
+		t.Fatalf("expected NoCompletionError within 1, got %v", err)
+	}
+	if _, err := m.FindCompletion([]rune("x"), -1); !errors.As(err, &nc) {
+		t.Fatalf("expected NoCompletionError from trap, got %v", err)
+	}
+	var te *TransitionError[string, rune]
+	if _, err := m.FindCompletion([]rune("cz"), 5); !errors.As(err, &te) {
+		t.Fatalf("expected TransitionError for invalid prefix, got %v", err)
+	}
+}
+
+func TestAcceptedLengthBounds(t *testing.T) {
+	var words [][]rune
+	for _, w := range []string{"tree", "trie", "to", "trellis"} {
+		words = append(words, []rune(w))
+	}
+	m, err := FromWords(words)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lo, hi, finite, err := m.AcceptedLengthBounds()
+	if err != nil || lo != 2 || hi != 7 || !finite {
+		t.Fatalf("expected 2 7 true, got %d %d %v %v", lo, hi, finite, err)
+	}
+
+	lo, hi, finite, err = buildMod3(t).AcceptedLengthBounds()
+	if err != nil || lo != 0 || hi != -1 || finite {
+		t.Fatalf("expected 0 -1 false for mod3, got %d %d %v %v", lo, hi, finite, err)
+	}
+
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'x', "A")
+	empty, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if _, _, _, err := empty.AcceptedLengthBounds(); !errors.Is(err, ErrEmptyLanguage) {
+		t.Fatalf("expected ErrEmptyLanguage, got %v", err)
+	}
+}
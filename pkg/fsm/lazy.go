@@ -0,0 +1,31 @@
+package fsm
+
+import "sync"
+
+// Lazy builds a machine on first use and caches it, or the build error, for
+// every later call. It is safe for concurrent use.
+type Lazy[S comparable, Sym comparable] struct {
+	get func() (*Machine[S, Sym], error)
+}
+
+// NewLazy returns a Lazy calling build at most once.
+func NewLazy[S comparable, Sym comparable](build func() (*Machine[S, Sym], error)) *Lazy[S, Sym] {
+	return &Lazy[S, Sym]{get: sync.OnceValues(build)}
+}
+
+// Get returns the machine, building it on the first call. Every call returns
+// the same machine and error.
+func (l *Lazy[S, Sym]) Get() (*Machine[S, Sym], error) {
+	return l.get()
+}
+
+func (l *Lazy[S, Sym]) isNil() bool { return l == nil }
+
+// MustGet is like Get but panics if the build failed.
+func (l *Lazy[S, Sym]) MustGet() *Machine[S, Sym] {
+	m, err := l.get()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
@@ -0,0 +1,61 @@
+package fsm
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLazyBuildsOnceConcurrently(t *testing.T) {
+	var calls atomic.Int32
+	lazy := NewLazy(func() (*Machine[string, rune], error) {
+		calls.Add(1)
+		return buildMod3(t), nil
+	})
+	machines := make([]*Machine[string, rune], 64)
+	var wg sync.WaitGroup
+	for i := range machines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := lazy.Get()
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			machines[i] = m
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected one build, got %d", n)
+	}
+	for _, m := range machines {
+		if m == nil || m != lazy.MustGet() {
+			t.Fatalf("expected every caller to get the same machine")
+		}
+	}
+}
+
+func TestLazyCachesError(t *testing.T) {
+	calls := 0
+	boom := errors.New("boom")
+	lazy := NewLazy(func() (*Machine[int, int], error) {
+		calls++
+		return nil, boom
+	})
+	for range 3 {
+		if _, err := lazy.Get(); !errors.Is(err, boom) {
+			t.Fatalf("expected the build error, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the failed build to be cached, got %d calls", calls)
+	}
+	defer func() {
+		if r := recover(); r != boom {
+			t.Fatalf("expected MustGet to panic with the build error, got %v", r)
+		}
+	}()
+	lazy.MustGet()
+}
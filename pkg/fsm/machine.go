@@ -1,18 +1,87 @@
 package fsm
 
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+)
+
 // TransitionKey represents a state-symbol pair for transition lookup
 type TransitionKey[S, Sym comparable] struct {
 	From   S
 	Symbol Sym
 }
 
+// Transition is a single edge of the transition function: From --Symbol--> To.
+type Transition[S, Sym comparable] struct {
+	From   S
+	Symbol Sym
+	To     S
+}
+
 // Machine is an immutable deterministic finite state machine.
 // States and symbols are generic and must be comparable (hashable) to be used as map keys.
 type Machine[S comparable, Sym comparable] struct {
 	initialState S
 	accepting    map[S]struct{}
+	symbols      map[Sym]struct{}
 	// Flat map with composite key for O(1) lookup
 	transitions map[TransitionKey[S, Sym]]S
+	// Every state, as a set and as the list returned by States.
+	states    map[S]struct{}
+	stateList []S
+
+	// Inverted index from target state to incoming (from, symbol) pairs, built
+	// lazily on first use.
+	predOnce     sync.Once
+	predecessors map[S][]TransitionKey[S, Sym]
+
+	// Namers from WithStateNamer and WithSymbolNamer, used in TransitionError
+	// messages.
+	stateNamer, symbolNamer func(any) string
+
+	// Options of the Build that produced the machine.
+	buildOptions BuildOptionsInfo
+}
+
+// newMachine assembles a Machine from its parts, taking ownership of the maps.
+// The initial state, accepting states and transition endpoints must all be in
+// states.
+func newMachine[S comparable, Sym comparable](initial S, states, accepting map[S]struct{}, symbols map[Sym]struct{}, transitions map[TransitionKey[S, Sym]]S) *Machine[S, Sym] {
+	list := make([]S, 0, len(states))
+	list = append(list, initial)
+	for _, s := range sortedKeys(states) {
+		if s != initial {
+			list = append(list, s)
+		}
+	}
+	return newMachineOrdered(initial, list, states, accepting, symbols, transitions)
+}
+
+// newMachineOrdered is like newMachine but uses list, which must hold every
+// state with the initial state first, as the order of States.
+func newMachineOrdered[S comparable, Sym comparable](initial S, list []S, states, accepting map[S]struct{}, symbols map[Sym]struct{}, transitions map[TransitionKey[S, Sym]]S) *Machine[S, Sym] {
+	return &Machine[S, Sym]{
+		initialState: initial,
+		accepting:    accepting,
+		symbols:      symbols,
+		transitions:  transitions,
+		states:       states,
+		stateList:    list,
+	}
+}
+
+// withNamers copies the state and symbol namers of src onto m, which is
+// derived from src with the same state and symbol types, and returns m.
+func (m *Machine[S, Sym]) withNamers(src *Machine[S, Sym]) *Machine[S, Sym] {
+	m.stateNamer, m.symbolNamer = src.stateNamer, src.symbolNamer
+	return m
 }
 
 // Start creates a new runner starting at the initial state.
@@ -23,17 +92,31 @@ func (m *Machine[S, Sym]) Start() *Runner[S, Sym] {
 	}
 }
 
+// StartAt creates a new runner starting at state, e.g. to resume an
+// interrupted evaluation. It fails with *UnknownStateError for a state not in
+// the machine.
+func (m *Machine[S, Sym]) StartAt(state S) (*Runner[S, Sym], error) {
+	if !m.hasState(state) {
+		return nil, &UnknownStateError{State: state}
+	}
+	return &Runner[S, Sym]{machine: m, state: state}, nil
+}
+
 // Accepting reports whether the provided state is in the accepting set.
 func (m *Machine[S, Sym]) Accepting(state S) bool {
 	_, ok := m.accepting[state]
 	return ok
 }
 
-// Eval consumes a sequence of symbols and returns the final state.
+// Eval consumes a sequence of symbols and returns the final state. A missing
+// transition is reported as a *TransitionError carrying the symbol's position.
 func (m *Machine[S, Sym]) Eval(input []Sym) (S, error) {
 	r := m.Start()
-	for _, sym := range input {
+	for i, sym := range input {
 		if err := r.Step(sym); err != nil {
+			if te, ok := err.(*TransitionError[S, Sym]); ok {
+				te.Position = i
+			}
 			var zero S
 			return zero, err
 		}
@@ -50,38 +133,203 @@ func (m *Machine[S, Sym]) EvalAccepting(input []Sym) (bool, error) {
 	return m.Accepting(finalState), nil
 }
 
-// Get all states in the machine
-func (m *Machine[S, Sym]) States() []S {
-	states := make([]S, 0, len(m.accepting)+1)
-	seen := make(map[S]struct{})
+// EvalSeq is Eval over a lazily consumed sequence, which is stopped at the
+// first missing transition; see Runner.StepSeq.
+func (m *Machine[S, Sym]) EvalSeq(seq iter.Seq[Sym]) (S, error) {
+	r := m.Start()
+	if err := r.StepSeq(seq); err != nil {
+		var zero S
+		return zero, err
+	}
+	return r.State(), nil
+}
+
+// EvalAcceptingSeq is EvalAccepting over a lazily consumed sequence.
+func (m *Machine[S, Sym]) EvalAcceptingSeq(seq iter.Seq[Sym]) (bool, error) {
+	finalState, err := m.EvalSeq(seq)
+	if err != nil {
+		return false, err
+	}
+	return m.Accepting(finalState), nil
+}
+
+// ContextCheckInterval is the number of symbols EvalContext steps between two
+// checks of its context.
+const ContextCheckInterval = 4096
+
+// EvalContext is Eval checking ctx before the first symbol and then every
+// ContextCheckInterval symbols. When ctx is done it returns the zero state and
+// an *InterruptedError wrapping ctx.Err(), from which the evaluation can be
+// resumed.
+func (m *Machine[S, Sym]) EvalContext(ctx context.Context, input []Sym) (S, error) {
+	var zero S
+	r := m.Start()
+	for i, sym := range input {
+		if i%ContextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return zero, r.interrupted(i, err)
+			}
+		}
+		if err := r.Step(sym); err != nil {
+			if te, ok := err.(*TransitionError[S, Sym]); ok {
+				te.Position = i
+			}
+			return zero, err
+		}
+	}
+	return r.State(), nil
+}
+
+// EvalAcceptingContext is EvalAccepting with the interruption of EvalContext.
+func (m *Machine[S, Sym]) EvalAcceptingContext(ctx context.Context, input []Sym) (bool, error) {
+	finalState, err := m.EvalContext(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	return m.Accepting(finalState), nil
+}
+
+// EvalChan is Eval over the symbols received from ch until it is closed; see
+// Runner.Feed. When ctx is done it returns the state reached so far together
+// with the *InterruptedError; after a missing transition it returns the zero
+// state, as Eval does.
+func (m *Machine[S, Sym]) EvalChan(ctx context.Context, ch <-chan Sym) (S, error) {
+	r := m.Start()
+	err := r.Feed(ctx, ch)
+	if _, ok := err.(*TransitionError[S, Sym]); ok {
+		var zero S
+		return zero, err
+	}
+	return r.State(), err
+}
+
+// EvalString is Eval for rune machines, ranging over the runes of s instead of
+// converting it to a []rune. Positions in errors count runes, as for
+// Eval([]rune(s)).
+func EvalString[S comparable](m *Machine[S, rune], s string) (S, error) {
+	r := m.Start()
+	i := 0
+	for _, sym := range s {
+		if err := r.Step(sym); err != nil {
+			if te, ok := err.(*TransitionError[S, rune]); ok {
+				te.Position = i
+			}
+			var zero S
+			return zero, err
+		}
+		i++
+	}
+	return r.State(), nil
+}
 
-	// Add initial state first
-	states = append(states, m.initialState)
-	seen[m.initialState] = struct{}{}
+// EvalAcceptingString is EvalAccepting for rune machines reading a string.
+func EvalAcceptingString[S comparable](m *Machine[S, rune], s string) (bool, error) {
+	finalState, err := EvalString(m, s)
+	if err != nil {
+		return false, err
+	}
+	return m.Accepting(finalState), nil
+}
 
-	// Add accepting states
-	for state := range m.accepting {
-		if _, exists := seen[state]; !exists {
-			states = append(states, state)
-			seen[state] = struct{}{}
+// EvalReader is Eval for byte machines reading their input from r through a
+// bufio.Reader, so the input never has to fit in memory. A missing transition
+// is reported as a *TransitionError whose Position is the byte offset; a read
+// error is returned wrapped, with the offset, and is never a TransitionError.
+func EvalReader[S comparable](m *Machine[S, byte], r io.Reader) (S, error) {
+	var zero S
+	br := bufio.NewReader(r)
+	run := m.Start()
+	for pos := 0; ; pos++ {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			return run.State(), nil
+		}
+		if err != nil {
+			return zero, fmt.Errorf("reading input at byte %d: %w", pos, err)
+		}
+		if err := run.Step(c); err != nil {
+			if te, ok := err.(*TransitionError[S, byte]); ok {
+				te.Position = pos
+			}
+			return zero, err
 		}
 	}
+}
+
+// AcceptsOption configures AcceptsAll and AcceptsNone.
+type AcceptsOption func(*acceptsOptions)
+
+type acceptsOptions struct {
+	propagateErrors bool
+	shortCircuit    bool
+}
+
+// WithTransitionErrors makes a missing transition stop the evaluation and be
+// returned as an error instead of counting as rejection.
+func WithTransitionErrors() AcceptsOption {
+	return func(o *acceptsOptions) { o.propagateErrors = true }
+}
 
-	// Add any other states from transitions
-	for key, to := range m.transitions {
-		// Add 'from' state
-		if _, exists := seen[key.From]; !exists {
-			states = append(states, key.From)
-			seen[key.From] = struct{}{}
+// WithShortCircuit stops at the first input violating the expectation.
+func WithShortCircuit() AcceptsOption {
+	return func(o *acceptsOptions) { o.shortCircuit = true }
+}
+
+// checkAll evaluates inputs and returns the indices of those whose acceptance
+// differs from want.
+func (m *Machine[S, Sym]) checkAll(inputs [][]Sym, want bool, opts []AcceptsOption) (bool, []int, error) {
+	var o acceptsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var violations []int
+	for i, input := range inputs {
+		ok, err := m.EvalAccepting(input)
+		if err != nil && o.propagateErrors {
+			return false, violations, err
 		}
-		// Add 'to' state
-		if _, exists := seen[to]; !exists {
-			states = append(states, to)
-			seen[to] = struct{}{}
+		if ok != want {
+			violations = append(violations, i)
+			if o.shortCircuit {
+				break
+			}
 		}
 	}
+	return len(violations) == 0, violations, nil
+}
 
-	return states
+// AcceptsAll reports whether every input is accepted, together with the
+// indices of the inputs that are not. By default an input hitting a missing
+// transition is simply rejected; see WithTransitionErrors and WithShortCircuit.
+func (m *Machine[S, Sym]) AcceptsAll(inputs [][]Sym, opts ...AcceptsOption) (bool, []int, error) {
+	return m.checkAll(inputs, true, opts)
+}
+
+// AcceptsNone reports whether every input is rejected, together with the
+// indices of the inputs that are accepted. Options are as for AcceptsAll.
+func (m *Machine[S, Sym]) AcceptsNone(inputs [][]Sym, opts ...AcceptsOption) (bool, []int, error) {
+	return m.checkAll(inputs, false, opts)
+}
+
+// States returns every state of the machine, including declared states
+// without transitions. The initial state comes first and the others follow in
+// the package's deterministic order, or in the order given by WithStateLess
+// when the machine was built with it. The slice is a copy.
+func (m *Machine[S, Sym]) States() []S {
+	return slices.Clone(m.stateList)
+}
+
+// Symbols returns the input alphabet of the machine in the package's
+// deterministic order.
+func (m *Machine[S, Sym]) Symbols() []Sym {
+	return m.sortedSymbols()
+}
+
+// BuildOptions reports the options of the Build that produced the machine. It
+// is the zero value for machines derived by other operations, such as
+// Minimize, which do not rerun the build checks.
+func (m *Machine[S, Sym]) BuildOptions() BuildOptionsInfo {
+	return m.buildOptions
 }
 
 // Get the initial state
@@ -102,3 +350,103 @@ func (m *Machine[S, Sym]) HasTransition(from S, symbol Sym) bool {
 }
 
 
+
+// Transitions yields every transition of the machine, ordered by source state
+// and then symbol under the package's deterministic order.
+func (m *Machine[S, Sym]) Transitions() iter.Seq[Transition[S, Sym]] {
+	return func(yield func(Transition[S, Sym]) bool) {
+		all := make([]Transition[S, Sym], 0, len(m.transitions))
+		for key, to := range m.transitions {
+			all = append(all, Transition[S, Sym]{From: key.From, Symbol: key.Symbol, To: to})
+		}
+		slices.SortFunc(all, compareTransitions[S, Sym])
+		for _, t := range all {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+// TransitionsFrom yields the transitions leaving state, ordered by symbol.
+func (m *Machine[S, Sym]) TransitionsFrom(state S) iter.Seq[Transition[S, Sym]] {
+	return func(yield func(Transition[S, Sym]) bool) {
+		for _, sym := range m.sortedSymbols() {
+			if to, ok := m.GetTransition(state, sym); ok {
+				if !yield(Transition[S, Sym]{From: state, Symbol: sym, To: to}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// DefinedSymbols returns, in deterministic order, the symbols on which state has
+// an outgoing transition. It is empty for unknown states and states without
+// outgoing transitions.
+func (m *Machine[S, Sym]) DefinedSymbols(state S) []Sym {
+	syms := []Sym{}
+	for t := range m.TransitionsFrom(state) {
+		syms = append(syms, t.Symbol)
+	}
+	return syms
+}
+
+// OutDegree returns the number of transitions leaving state.
+func (m *Machine[S, Sym]) OutDegree(state S) int {
+	n := 0
+	for sym := range m.symbols {
+		if m.HasTransition(state, sym) {
+			n++
+		}
+	}
+	return n
+}
+
+// predecessorIndex returns the inverted transition index, building it once.
+func (m *Machine[S, Sym]) predecessorIndex() map[S][]TransitionKey[S, Sym] {
+	m.predOnce.Do(func() {
+		index := make(map[S][]TransitionKey[S, Sym])
+		for key, to := range m.transitions {
+			index[to] = append(index[to], key)
+		}
+		for _, keys := range index {
+			slices.SortFunc(keys, func(a, b TransitionKey[S, Sym]) int {
+				if c := compareValues(a.From, b.From); c != 0 {
+					return c
+				}
+				return compareValues(a.Symbol, b.Symbol)
+			})
+		}
+		m.predecessors = index
+	})
+	return m.predecessors
+}
+
+// Predecessors returns the (from, symbol) pairs whose transition leads to state,
+// ordered by source state and then symbol. A self-loop lists the state as its
+// own predecessor. The result must not be modified.
+func (m *Machine[S, Sym]) Predecessors(state S) []TransitionKey[S, Sym] {
+	return m.predecessorIndex()[state]
+}
+
+// InDegree returns the number of transitions leading to state.
+func (m *Machine[S, Sym]) InDegree(state S) int {
+	return len(m.predecessorIndex()[state])
+}
+
+// hasState reports whether state belongs to the machine.
+func (m *Machine[S, Sym]) hasState(state S) bool {
+	_, ok := m.states[state]
+	return ok
+}
+
+// SortedStates returns the states of m in their natural ascending order.
+func SortedStates[S cmp.Ordered, Sym comparable](m *Machine[S, Sym]) []S {
+	return slices.Sorted(maps.Keys(m.states))
+}
+
+// SortedSymbols returns the alphabet of m in its natural ascending order.
+func SortedSymbols[S comparable, Sym cmp.Ordered](m *Machine[S, Sym]) []Sym {
+	return slices.Sorted(maps.Keys(m.symbols))
+}
@@ -1,6 +1,17 @@
 package fsm
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
 
 func TestMachineEvalMod3States(t *testing.T) {
 	b := NewBuilder[string, rune](WithPreventOverwriteTransitions())
@@ -233,3 +244,472 @@ func TestHasTransitionMethod(t *testing.T) {
 }
 
 
+
+func TestSymbolsMethod(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddSymbol('x')
+	b.On("A", 'y', "A") // 'y' registered implicitly
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	syms := m.Symbols()
+	if len(syms) != 2 {
+		t.Fatalf("expected 2 symbols, got %v", syms)
+	}
+	for _, expected := range []rune{'x', 'y'} {
+		found := false
+		for _, sym := range syms {
+			if sym == expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("expected symbol %q not found in %v", expected, syms)
+		}
+	}
+}
+
+func TestTransitionsIterator(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("B")
+	b.On("B", 'y', "A").On("A", 'y', "B").On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	want := []Transition[string, rune]{
+		{From: "A", Symbol: 'x', To: "A"},
+		{From: "A", Symbol: 'y', To: "B"},
+		{From: "B", Symbol: 'y', To: "A"},
+	}
+	for i := 0; i < 5; i++ {
+		if got := slices.Collect(m.Transitions()); !slices.Equal(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if got := slices.Collect(m.TransitionsFrom("A")); !slices.Equal(got, want[:2]) {
+		t.Fatalf("expected %v, got %v", want[:2], got)
+	}
+	if got := slices.Collect(m.TransitionsFrom("Unknown")); len(got) != 0 {
+		t.Fatalf("expected no transitions from unknown state, got %v", got)
+	}
+	for tr := range m.Transitions() {
+		if tr.From != "A" || tr.Symbol != 'x' {
+			t.Fatalf("expected first transition A --x--> A, got %v", tr)
+		}
+		break
+	}
+}
+
+func TestDefinedSymbolsAndOutDegree(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddSymbol('z')
+	b.On("A", 'y', "B").On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	cases := []struct {
+		state string
+		syms  []rune
+	}{
+		{"A", []rune{'x', 'y'}},
+		{"B", []rune{}},
+		{"Unknown", []rune{}},
+	}
+	for _, tc := range cases {
+		got := m.DefinedSymbols(tc.state)
+		if got == nil || !slices.Equal(got, tc.syms) {
+			t.Fatalf("%s: expected symbols %q, got %q", tc.state, tc.syms, got)
+		}
+		if deg := m.OutDegree(tc.state); deg != len(tc.syms) {
+			t.Fatalf("%s: expected out-degree %d, got %d", tc.state, len(tc.syms), deg)
+		}
+	}
+}
+
+func TestPredecessorsAndInDegree(t *testing.T) {
+	m := buildMod3(t)
+	want := []TransitionKey[string, rune]{
+		{From: "S0", Symbol: '1'},
+		{From: "S2", Symbol: '0'},
+	}
+	if got := m.Predecessors("S1"); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	// Self-loops count the state as its own predecessor.
+	want = []TransitionKey[string, rune]{
+		{From: "S0", Symbol: '0'},
+		{From: "S1", Symbol: '1'},
+	}
+	if got := m.Predecessors("S0"); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for s, deg := range map[string]int{"S0": 2, "S1": 2, "S2": 2, "Unknown": 0} {
+		if got := m.InDegree(s); got != deg {
+			t.Fatalf("%s: expected in-degree %d, got %d", s, deg, got)
+		}
+	}
+
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'x', "B")
+	m2, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := m2.Predecessors("A"); len(got) != 0 {
+		t.Fatalf("expected no predecessors of initial state, got %v", got)
+	}
+	if got := m2.InDegree("A"); got != 0 {
+		t.Fatalf("expected in-degree 0, got %d", got)
+	}
+}
+
+func TestStatesIncludesIsolatedStates(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("B")
+	b.AddState("Isolated", false).AddState("Z", true)
+	b.On("B", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	want := []string{"B", "A", "Isolated", "Z"}
+	got := m.States()
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	got[0] = "mutated"
+	if m.States()[0] != "B" {
+		t.Fatalf("expected States to return a copy")
+	}
+	if !slices.Contains(m.TerminalStates(), "Isolated") {
+		t.Fatalf("expected isolated state to be terminal")
+	}
+}
+
+func BenchmarkStates(b *testing.B) {
+	bld := NewBuilder[int, int]()
+	bld.SetInitial(0)
+	for s := 0; s < 200; s++ {
+		for sym := 0; sym < 10; sym++ {
+			bld.On(s, sym, (s+sym+1)%200)
+		}
+	}
+	m, err := bld.Build()
+	if err != nil {
+		b.Fatalf("unexpected build error: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.States()
+	}
+}
+
+func TestSortedStatesAndSymbols(t *testing.T) {
+	b := NewBuilder[int, rune]()
+	b.SetInitial(5)
+	b.AddState(1, false)
+	b.On(5, 'z', 3).On(3, 'a', 5).On(3, 'm', 10)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got, want := SortedStates(m), []int{1, 3, 5, 10}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got, want := SortedSymbols(m), []rune{'a', 'm', 'z'}; !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got, want := m.Symbols(), []rune{'a', 'm', 'z'}; !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestStateLessReproducibleOutput(t *testing.T) {
+	type cell struct{ Row, Col int }
+	// Column-major order, which differs from the default formatted order.
+	less := func(a, b cell) bool {
+		if a.Col != b.Col {
+			return a.Col < b.Col
+		}
+		return a.Row < b.Row
+	}
+	cells := []cell{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}}
+	render := func(rotate int) string {
+		b := NewBuilder[cell, rune](WithStateLess(less))
+		b.SetInitial(cells[0])
+		for i := range cells {
+			from := cells[(i+rotate)%len(cells)]
+			b.On(from, 'n', cells[(i+rotate+1)%len(cells)])
+			b.AddState(from, from.Row == 1)
+		}
+		m, err := b.Build()
+		if err != nil {
+			t.Fatalf("unexpected build error: %v", err)
+		}
+		return fmt.Sprintf("%v\n%v\n%v", m.States(), m.Symbols(), m.EdgeList())
+	}
+	want := render(0)
+	if !strings.HasPrefix(want, "[{0 0} {1 0} {2 0} {0 1} {1 1}]") {
+		t.Fatalf("expected column-major state order, got %s", want)
+	}
+	for i := 1; i < 20; i++ {
+		if got := render(i); got != want {
+			t.Fatalf("output differs between builds:\n%s\nvs\n%s", want, got)
+		}
+	}
+}
+
+func TestStateLessRejectsMistypedComparator(t *testing.T) {
+	b := NewBuilder[int64, rune](WithStateLess(func(a, b int) bool { return a > b }))
+	b.SetInitial(0).On(0, 'x', 1)
+	_, err := b.Build()
+	if !errors.Is(err, ErrInvalidDefinition) || !strings.Contains(err.Error(), "WithStateLess: comparator is a func(int, int) bool, not a func(a, b int64) bool") {
+		t.Fatalf("expected a comparator type error, got %v", err)
+	}
+}
+
+func TestAcceptsAllAndNone(t *testing.T) {
+	m := buildMod3(t)
+	runes := func(words ...string) [][]rune {
+		out := make([][]rune, len(words))
+		for i, w := range words {
+			out[i] = []rune(w)
+		}
+		return out
+	}
+	ok, bad, err := m.AcceptsAll(runes("", "11", "110", "1001"))
+	if err != nil || !ok || len(bad) != 0 {
+		t.Fatalf("expected all accepted, got %v %v %v", ok, bad, err)
+	}
+	ok, bad, err = m.AcceptsAll(runes("11", "1", "x", "10"))
+	if err != nil || ok || !slices.Equal(bad, []int{1, 2, 3}) {
+		t.Fatalf("expected violations [1 2 3], got %v %v %v", ok, bad, err)
+	}
+	ok, bad, _ = m.AcceptsAll(runes("11", "1", "x", "10"), WithShortCircuit())
+	if ok || !slices.Equal(bad, []int{1}) {
+		t.Fatalf("expected short-circuit at [1], got %v %v", ok, bad)
+	}
+	var te *TransitionError[string, rune]
+	_, bad, err = m.AcceptsAll(runes("11", "x1", "1"), WithTransitionErrors())
+	if !errors.As(err, &te) || len(bad) != 0 {
+		t.Fatalf("expected TransitionError before any violation, got %v %v", bad, err)
+	}
+
+	ok, bad, err = m.AcceptsNone(runes("1", "10", "x", "11"))
+	if err != nil || ok || !slices.Equal(bad, []int{3}) {
+		t.Fatalf("expected violation [3], got %v %v %v", ok, bad, err)
+	}
+	ok, _, err = m.AcceptsNone(runes("1", "10", "x"))
+	if err != nil || !ok {
+		t.Fatalf("expected all rejected, got %v %v", ok, err)
+	}
+}
+
+func TestBuildOptionsRoundTrip(t *testing.T) {
+	mod3 := buildMod3(t)
+	if got := mod3.Minimize().BuildOptions(); got != (BuildOptionsInfo{}) {
+		t.Fatalf("expected derived machines to carry no options, got %+v", got)
+	}
+	for field, opt := range map[string]Option{
+		"PreventOverwriteTransitions":   WithPreventOverwriteTransitions(),
+		"CollectOverwriteErrors":        WithCollectOverwriteErrors(),
+		"ErrorOnOverwriteTransitions":   WithErrorOnOverwriteTransitions(),
+		"StrictRegistration":            WithStrictRegistration(),
+		"EagerValidation":               WithEagerValidation(),
+		"CompleteWithSink":              WithCompleteWithSink("sink"),
+		"RequireTotalTransitions":       WithRequireTotalTransitions(),
+		"RequireAtLeastOneAccepting":    WithRequireAtLeastOneAccepting(),
+		"ErrorOnUnreachableStates":      WithErrorOnUnreachableStates(),
+		"ErrorWhenNoAcceptingReachable": WithErrorWhenNoAcceptingReachable(),
+		"ErrorOnDeadTransitions":        WithErrorOnDeadTransitions(),
+		"ErrorOnDeadStates":             WithErrorOnDeadStates(),
+		"RequireAllStatesUseful":        WithRequireAllStatesUseful(),
+		"ErrorOnTerminalStates":         WithErrorOnTerminalStates[string](),
+		"RequireInitialOutgoing":        WithRequireInitialOutgoing(),
+		"ErrorOnUnusedSymbols":          WithErrorOnUnusedSymbols(),
+		"AllowEmptyAlphabet":            WithAllowEmptyAlphabet(),
+		"WarningsAsErrors":              WithWarningsAsErrors(),
+		"MaxValidationErrors":           WithMaxValidationErrors(3),
+	} {
+		m, err := mod3.ToBuilder(opt).Build()
+		if err != nil {
+			t.Fatalf("%s: unexpected build error: %v", field, err)
+		}
+		info := m.BuildOptions()
+		v := reflect.ValueOf(info)
+		for i := range v.NumField() {
+			if set := !v.Field(i).IsZero(); set != (v.Type().Field(i).Name == field) {
+				t.Errorf("%s: field %s set=%v", field, v.Type().Field(i).Name, set)
+			}
+		}
+		data, err := json.Marshal(info)
+		if err != nil {
+			t.Fatalf("%s: unexpected marshal error: %v", field, err)
+		}
+		var decoded BuildOptionsInfo
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != info {
+			t.Errorf("%s: JSON round trip gave %+v, %v from %s", field, decoded, err, data)
+		}
+	}
+}
+
+func TestEvalStringMatchesEval(t *testing.T) {
+	m := buildMod3(t)
+	for _, in := range []string{"", "0", "1101", "1111", "101010"} {
+		want, _ := m.Eval([]rune(in))
+		if got, err := EvalString(m, in); err != nil || got != want {
+			t.Errorf("%q: want %v, got %v, %v", in, want, got, err)
+		}
+		wantOK, _ := m.EvalAccepting([]rune(in))
+		if ok, err := EvalAcceptingString(m, in); err != nil || ok != wantOK {
+			t.Errorf("%q: want accepting=%v, got %v, %v", in, wantOK, ok, err)
+		}
+	}
+	// Positions count runes, not bytes.
+	var terr *TransitionError[string, rune]
+	if _, err := EvalString(m, "1é0"); !errors.As(err, &terr) || terr.Position != 1 || terr.Symbol != 'é' {
+		t.Fatalf("expected a transition error at rune 1, got %v", err)
+	}
+	if ok, err := EvalAcceptingString(m, "12"); ok || err == nil {
+		t.Fatalf("expected an error for an unknown symbol, got %v, %v", ok, err)
+	}
+}
+
+func benchmarkEvalInput() string {
+	return strings.Repeat("1101", 4096)
+}
+
+func BenchmarkEvalRuneSlice(b *testing.B) {
+	m := buildMod3(b)
+	in := benchmarkEvalInput()
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := m.Eval([]rune(in)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEvalString(b *testing.B) {
+	m := buildMod3(b)
+	in := benchmarkEvalInput()
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := EvalString(m, in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func buildMod3Bytes(t testing.TB) *Machine[string, byte] {
+	t.Helper()
+	m, err := MapSymbols(buildMod3(t), func(r rune) byte { return byte(r) })
+	if err != nil {
+		t.Fatalf("unexpected map error: %v", err)
+	}
+	return m
+}
+
+// onesReader yields n '1' bytes without materializing them.
+type onesReader struct{ n int }
+
+func (r *onesReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, io.EOF
+	}
+	k := min(len(p), r.n)
+	for i := range k {
+		p[i] = '1'
+	}
+	r.n -= k
+	return k, nil
+}
+
+func TestEvalReader(t *testing.T) {
+	m := buildMod3Bytes(t)
+	for _, in := range []string{"", "1101", "1111", "101010"} {
+		want, _ := m.Eval([]byte(in))
+		if got, err := EvalReader(m, iotest.OneByteReader(strings.NewReader(in))); err != nil || got != want {
+			t.Errorf("%q: want %v, got %v, %v", in, want, got, err)
+		}
+	}
+	// 2^n - 1 is divisible by three exactly when n is even.
+	const n = 8<<20 + 1
+	if got, err := EvalReader(m, &onesReader{n: n}); err != nil || got != "S1" {
+		t.Fatalf("expected S1 for %d ones, got %v, %v", n, got, err)
+	}
+	var terr *TransitionError[string, byte]
+	_, err := EvalReader(m, io.MultiReader(&onesReader{n: 5000}, strings.NewReader("2")))
+	if !errors.As(err, &terr) || terr.Position != 5000 {
+		t.Fatalf("expected a transition error at byte 5000, got %v", err)
+	}
+	boom := errors.New("boom")
+	_, err = EvalReader(m, io.MultiReader(strings.NewReader("11"), iotest.ErrReader(boom)))
+	if !errors.Is(err, boom) || errors.As(err, &terr) || !strings.Contains(err.Error(), "at byte 2") {
+		t.Fatalf("expected a wrapped read error at byte 2, got %v", err)
+	}
+}
+
+// expiringContext reports itself cancelled from its n-th Err call on.
+type expiringContext struct {
+	context.Context
+	n int
+}
+
+func (c *expiringContext) Err() error {
+	if c.n--; c.n <= 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestEvalContext(t *testing.T) {
+	m := buildMod3(t)
+	for _, in := range []string{"", "1101", "1111", "12"} {
+		want, wantErr := m.Eval([]rune(in))
+		got, err := m.EvalContext(context.Background(), []rune(in))
+		if got != want || fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			t.Errorf("%q: want %v, %v; got %v, %v", in, want, wantErr, got, err)
+		}
+		wantOK, _ := m.EvalAccepting([]rune(in))
+		if ok, _ := m.EvalAcceptingContext(context.Background(), []rune(in)); ok != wantOK {
+			t.Errorf("%q: want accepting=%v, got %v", in, wantOK, ok)
+		}
+	}
+
+	huge := []rune(strings.Repeat("1", 1<<22))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var ierr *InterruptedError[string]
+	if _, err := m.EvalContext(ctx, huge); !errors.As(err, &ierr) || ierr.Index != 0 || ierr.State != "S0" || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected an immediate interruption, got %v", err)
+	}
+	if ok, err := m.EvalAcceptingContext(ctx, huge); ok || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected an interruption, got %v, %v", ok, err)
+	}
+
+	// Interrupt at the third check and resume from the reported state.
+	_, err := m.EvalContext(&expiringContext{Context: context.Background(), n: 3}, huge)
+	if !errors.As(err, &ierr) || ierr.Index != 2*ContextCheckInterval {
+		t.Fatalf("expected an interruption at index %d, got %v", 2*ContextCheckInterval, err)
+	}
+	r, err := m.StartAt(ierr.State)
+	if err != nil {
+		t.Fatalf("unexpected StartAt error: %v", err)
+	}
+	if err := r.StepSeq(slices.Values(huge[ierr.Index:])); err != nil {
+		t.Fatalf("unexpected resume error: %v", err)
+	}
+	if want, _ := m.Eval(huge); r.State() != want {
+		t.Fatalf("expected the resumed run to end in %v, got %v", want, r.State())
+	}
+	if _, err := m.StartAt("S9"); err == nil {
+		t.Fatalf("expected an error for an unknown state")
+	}
+}
@@ -0,0 +1,81 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Minimize returns the minimal deterministic machine recognizing the same
+// language. States that are unreachable or cannot lead to acceptance are
+// removed, so the result is partial: a missing transition means rejection.
+// Equivalent states are merged by partition refinement and the result is
+// numbered 0..n-1 as by Normalize with the default symbol order, making it
+// canonical for its language and alphabet. An empty language yields a single
+// non-accepting initial state without transitions.
+func (m *Machine[S, Sym]) Minimize() *Machine[int, Sym] {
+	trimmed, err := m.Trim()
+	if err != nil {
+		syms := make(map[Sym]struct{}, len(m.symbols))
+		for sym := range m.symbols {
+			syms[sym] = struct{}{}
+		}
+		empty := newMachine(0, map[int]struct{}{0: {}}, map[int]struct{}{}, syms, map[TransitionKey[int, Sym]]int{})
+		empty.symbolNamer = m.symbolNamer
+		return empty
+	}
+
+	states := sortedKeys(trimmed.reachable(trimmed.initialState))
+	syms := trimmed.sortedSymbols()
+	class := make(map[S]int, len(states))
+	for _, s := range states {
+		if trimmed.Accepting(s) {
+			class[s] = 1
+		}
+	}
+	count := 0
+	for {
+		// Refine: states stay together only if they agree on their class and on
+		// the class reached by every symbol (-1 for a missing transition).
+		next := make(map[S]int, len(states))
+		ids := make(map[string]int)
+		for _, s := range states {
+			var sig strings.Builder
+			fmt.Fprintf(&sig, "%d", class[s])
+			for _, sym := range syms {
+				target := -1
+				if to, ok := trimmed.GetTransition(s, sym); ok {
+					target = class[to]
+				}
+				fmt.Fprintf(&sig, ",%d", target)
+			}
+			id, ok := ids[sig.String()]
+			if !ok {
+				id = len(ids)
+				ids[sig.String()] = id
+			}
+			next[s] = id
+		}
+		class = next
+		if len(ids) == count {
+			break
+		}
+		count = len(ids)
+	}
+
+	classes := make(map[int]struct{})
+	accepting := make(map[int]struct{})
+	for _, s := range states {
+		classes[class[s]] = struct{}{}
+		if trimmed.Accepting(s) {
+			accepting[class[s]] = struct{}{}
+		}
+	}
+	transitions := make(map[TransitionKey[int, Sym]]int)
+	for key, to := range trimmed.transitions {
+		transitions[TransitionKey[int, Sym]{From: class[key.From], Symbol: key.Symbol}] = class[to]
+	}
+	quotient := newMachine(class[trimmed.initialState], classes, accepting, trimmed.symbols, transitions)
+	quotient.symbolNamer = m.symbolNamer
+	minimal, _ := quotient.Normalize(nil)
+	return minimal
+}
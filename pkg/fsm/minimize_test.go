@@ -0,0 +1,55 @@
+package fsm
+
+import "testing"
+
+func TestMinimizeMergesEquivalentStates(t *testing.T) {
+	// Even number of 'a's, with the even/odd classes each split in two copies,
+	// plus an unreachable state and a dead trap.
+	b := NewBuilder[string, rune]()
+	b.SetInitial("E1")
+	b.AddState("E1", true).AddState("E2", true)
+	b.On("E1", 'a', "O1").On("O1", 'a', "E2").On("E2", 'a', "O2").On("O2", 'a', "E1")
+	b.On("E1", 'b', "E2").On("E2", 'b', "E1").On("O1", 'b', "O2").On("O2", 'b', "O1")
+	b.On("E1", 'c', "Trap").On("Trap", 'c', "Trap")
+	b.On("Lost", 'a', "E1")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	mm := m.Minimize()
+	if n := len(mm.States()); n != 2 {
+		t.Fatalf("expected 2 states, got %d", n)
+	}
+	if mm.InitialState() != 0 || !mm.Accepting(0) || mm.Accepting(1) {
+		t.Fatalf("unexpected minimal machine shape")
+	}
+	if mm.HasTransition(0, 'c') {
+		t.Fatalf("expected dead transitions to be removed")
+	}
+	assertSameLanguage(t, m, mm, []rune{'a', 'b', 'c'}, 6)
+}
+
+func TestMinimizeCanonical(t *testing.T) {
+	a := buildMod3(t).Minimize()
+	b := buildMod3(t).Minimize()
+	if dumpMachine(a) != dumpMachine(b) {
+		t.Fatalf("expected identical minimal machines:\n%s\n%s", dumpMachine(a), dumpMachine(b))
+	}
+	if n := len(a.States()); n != 3 {
+		t.Fatalf("mod3 is already minimal, expected 3 states, got %d", n)
+	}
+}
+
+func TestMinimizeEmptyLanguage(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'x', "B")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	mm := m.Minimize()
+	if len(mm.States()) != 1 || mm.Accepting(0) || mm.HasTransition(0, 'x') {
+		t.Fatalf("expected single rejecting state for empty language")
+	}
+}
@@ -0,0 +1,363 @@
+package fsm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NFA is an immutable nondeterministic finite automaton. A (state, symbol) pair
+// may lead to several states, states may have epsilon transitions that consume
+// no input, and there may be several initial states.
+type NFA[S comparable, Sym comparable] struct {
+	states      map[S]struct{}
+	symbols     map[Sym]struct{}
+	initial     map[S]struct{}
+	accepting   map[S]struct{}
+	transitions map[TransitionKey[S, Sym]]map[S]struct{}
+	epsilon     map[S]map[S]struct{}
+}
+
+// Set is a set of states.
+type Set[S comparable] map[S]struct{}
+
+// NewSet returns a set holding the given states.
+func NewSet[S comparable](states ...S) Set[S] {
+	set := make(Set[S], len(states))
+	for _, s := range states {
+		set[s] = struct{}{}
+	}
+	return set
+}
+
+// Contains reports whether s is in the set.
+func (set Set[S]) Contains(s S) bool {
+	_, ok := set[s]
+	return ok
+}
+
+// Sorted returns the members in deterministic order.
+func (set Set[S]) Sorted() []S { return sortedKeys(set) }
+
+// SetState identifies a set of NFA states in a determinized machine. Its value
+// is the sorted, braced rendering of the members, e.g. "{q1,q3}".
+type SetState string
+
+// String returns the braced member list.
+func (s SetState) String() string { return string(s) }
+
+// InitialStates returns the initial states of the automaton.
+func (n *NFA[S, Sym]) InitialStates() []S { return sortedKeys(n.initial) }
+
+// Accepting reports whether the provided state is in the accepting set.
+func (n *NFA[S, Sym]) Accepting(state S) bool {
+	_, ok := n.accepting[state]
+	return ok
+}
+
+// Targets returns the states reachable from the given state on sym.
+func (n *NFA[S, Sym]) Targets(from S, sym Sym) []S {
+	return sortedKeys(n.transitions[TransitionKey[S, Sym]{From: from, Symbol: sym}])
+}
+
+func (n *NFA[S, Sym]) addTransition(from S, sym Sym, to S) {
+	key := TransitionKey[S, Sym]{From: from, Symbol: sym}
+	targets, ok := n.transitions[key]
+	if !ok {
+		targets = make(map[S]struct{})
+		n.transitions[key] = targets
+	}
+	targets[to] = struct{}{}
+}
+
+func (n *NFA[S, Sym]) addEpsilon(from S, to S) {
+	targets, ok := n.epsilon[from]
+	if !ok {
+		targets = make(map[S]struct{})
+		n.epsilon[from] = targets
+	}
+	targets[to] = struct{}{}
+}
+
+// closure extends set in place with every state reachable through epsilon transitions.
+func (n *NFA[S, Sym]) closure(set map[S]struct{}) map[S]struct{} {
+	stack := make([]S, 0, len(set))
+	for s := range set {
+		stack = append(stack, s)
+	}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for to := range n.epsilon[cur] {
+			if _, ok := set[to]; !ok {
+				set[to] = struct{}{}
+				stack = append(stack, to)
+			}
+		}
+	}
+	return set
+}
+
+// EpsilonClosure returns the states reachable from states through epsilon
+// transitions alone, the states themselves included. The argument is not modified.
+func (n *NFA[S, Sym]) EpsilonClosure(states Set[S]) Set[S] {
+	out := make(Set[S], len(states))
+	for s := range states {
+		out[s] = struct{}{}
+	}
+	return n.closure(out)
+}
+
+// Eval simulates the automaton on input without building the powerset machine,
+// returning the set of states reached and whether any of them is accepting.
+// A symbol outside the alphabet yields a *TransitionError[Set[S], Sym] whose
+// From is the current state set. Running out of states is not an error: the input is
+// simply rejected.
+func (n *NFA[S, Sym]) Eval(input []Sym) (Set[S], bool, error) {
+	cur := n.EpsilonClosure(n.initial)
+	for i, sym := range input {
+		if _, ok := n.symbols[sym]; !ok {
+			return nil, false, &TransitionError[Set[S], Sym]{From: cur, Symbol: sym, Position: i}
+		}
+		next := make(Set[S])
+		for s := range cur {
+			for to := range n.transitions[TransitionKey[S, Sym]{From: s, Symbol: sym}] {
+				next[to] = struct{}{}
+			}
+		}
+		cur = n.closure(next)
+	}
+	for s := range cur {
+		if n.Accepting(s) {
+			return cur, true, nil
+		}
+	}
+	return cur, false, nil
+}
+
+func newNFA[S comparable, Sym comparable]() *NFA[S, Sym] {
+	return &NFA[S, Sym]{
+		states:      make(map[S]struct{}),
+		symbols:     make(map[Sym]struct{}),
+		initial:     make(map[S]struct{}),
+		accepting:   make(map[S]struct{}),
+		transitions: make(map[TransitionKey[S, Sym]]map[S]struct{}),
+		epsilon:     make(map[S]map[S]struct{}),
+	}
+}
+
+// NFABuilder incrementally constructs an NFA.
+type NFABuilder[S comparable, Sym comparable] struct {
+	nfa *NFA[S, Sym]
+}
+
+// NewNFABuilder creates a new NFA builder.
+func NewNFABuilder[S comparable, Sym comparable]() *NFABuilder[S, Sym] {
+	return &NFABuilder[S, Sym]{nfa: newNFA[S, Sym]()}
+}
+
+// AddState registers a state. If isAccepting is true, it is added to the accepting set.
+func (b *NFABuilder[S, Sym]) AddState(state S, isAccepting bool) *NFABuilder[S, Sym] {
+	b.nfa.states[state] = struct{}{}
+	if isAccepting {
+		b.nfa.accepting[state] = struct{}{}
+	}
+	return b
+}
+
+// AddInitial adds a state to the initial set. The state is implicitly registered.
+func (b *NFABuilder[S, Sym]) AddInitial(state S) *NFABuilder[S, Sym] {
+	b.nfa.states[state] = struct{}{}
+	b.nfa.initial[state] = struct{}{}
+	return b
+}
+
+// AddSymbol registers an input symbol.
+func (b *NFABuilder[S, Sym]) AddSymbol(sym Sym) *NFABuilder[S, Sym] {
+	b.nfa.symbols[sym] = struct{}{}
+	return b
+}
+
+// On adds a transition: from --sym--> to. Several targets may be added for the
+// same (from, sym) pair. States and symbol are implicitly registered.
+func (b *NFABuilder[S, Sym]) On(from S, sym Sym, to S) *NFABuilder[S, Sym] {
+	b.nfa.states[from] = struct{}{}
+	b.nfa.states[to] = struct{}{}
+	b.nfa.symbols[sym] = struct{}{}
+	b.nfa.addTransition(from, sym, to)
+	return b
+}
+
+// OnEpsilon adds a transition from --ε--> to that consumes no input. States are implicitly registered.
+func (b *NFABuilder[S, Sym]) OnEpsilon(from S, to S) *NFABuilder[S, Sym] {
+	b.nfa.states[from] = struct{}{}
+	b.nfa.states[to] = struct{}{}
+	b.nfa.addEpsilon(from, to)
+	return b
+}
+
+// Build validates and returns an immutable NFA. The builder must not be reused afterwards.
+func (b *NFABuilder[S, Sym]) Build() (*NFA[S, Sym], error) {
+	verr := &ValidationErrors{}
+	if len(b.nfa.initial) == 0 {
+		verr.Append(newBuildError(KindMissingInitial, "at least one initial state is required"))
+	}
+	for s := range b.nfa.accepting {
+		if _, ok := b.nfa.states[s]; !ok {
+			verr.Append(newBuildError(KindUnknownAcceptingState, "accepting state unknown %v", s).withState(s))
+		}
+	}
+	if err := verr.AsError(); err != nil {
+		return nil, err
+	}
+	n := b.nfa
+	b.nfa = newNFA[S, Sym]()
+	return n, nil
+}
+
+// Reverse returns an automaton recognizing the reversed language: transitions
+// are flipped, the initial states are the original accepting states, and the
+// sole accepting state is the original initial state.
+func (m *Machine[S, Sym]) Reverse() *NFA[S, Sym] {
+	n := newNFA[S, Sym]()
+	for _, s := range m.States() {
+		n.states[s] = struct{}{}
+	}
+	for sym := range m.symbols {
+		n.symbols[sym] = struct{}{}
+	}
+	for s := range m.accepting {
+		n.initial[s] = struct{}{}
+	}
+	n.accepting[m.initialState] = struct{}{}
+	for key, to := range m.transitions {
+		n.addTransition(to, key.Symbol, key.From)
+	}
+	return n
+}
+
+// subsetName renders a sorted member list as a SetState.
+func subsetName[S comparable](members []S) SetState {
+	parts := make([]string, len(members))
+	for i, s := range members {
+		parts[i] = fmt.Sprintf("%v", s)
+	}
+	return SetState("{" + strings.Join(parts, ",") + "}")
+}
+
+// Determinize converts the automaton into an equivalent deterministic Machine
+// using the subset construction with epsilon-closure. Only subsets reachable
+// from the initial set become states, and the empty subset is never
+// materialized: a symbol leading nowhere simply has no transition. An error is
+// returned if two distinct subsets render to the same SetState.
+func (n *NFA[S, Sym]) Determinize() (*Machine[SetState, Sym], error) {
+	return determinize(n, subsetName[S])
+}
+
+// DeterminizeOption configures DeterminizeTo.
+type DeterminizeOption[S comparable, T comparable] func(*determinizeOptions[S, T])
+
+type determinizeOptions[S comparable, T comparable] struct {
+	namer func(members []S) T
+}
+
+// WithSubsetNamer names each subset of NFA states, given as a sorted member
+// list, with a value of the resulting machine's state type.
+func WithSubsetNamer[S comparable, T comparable](namer func(members []S) T) DeterminizeOption[S, T] {
+	return func(o *determinizeOptions[S, T]) { o.namer = namer }
+}
+
+// DeterminizeTo is like Determinize but lets the caller choose the state type
+// of the resulting machine through WithSubsetNamer. Without a namer, T must be
+// SetState or string and the sorted, braced member list is used. An error is
+// returned if the namer maps two distinct subsets to the same state.
+func DeterminizeTo[T comparable, S comparable, Sym comparable](n *NFA[S, Sym], opts ...DeterminizeOption[S, T]) (*Machine[T, Sym], error) {
+	var o determinizeOptions[S, T]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.namer == nil {
+		var zero T
+		switch any(zero).(type) {
+		case SetState, string:
+			o.namer = func(members []S) T {
+				name := subsetName(members)
+				if t, ok := any(name).(T); ok {
+					return t
+				}
+				return any(string(name)).(T)
+			}
+		default:
+			return nil, fmt.Errorf("no state namer given for state type %T", zero)
+		}
+	}
+	return determinize(n, o.namer)
+}
+
+func determinize[T comparable, S comparable, Sym comparable](n *NFA[S, Sym], namer func(members []S) T) (*Machine[T, Sym], error) {
+	syms := sortedKeys(n.symbols)
+	members := make(map[T][]S)
+
+	intern := func(set map[S]struct{}) (T, bool, error) {
+		sorted := sortedKeys(set)
+		name := namer(sorted)
+		if prev, ok := members[name]; ok {
+			if !slices.Equal(prev, sorted) {
+				return name, false, fmt.Errorf("distinct state sets %v and %v share the name %v", prev, sorted, name)
+			}
+			return name, false, nil
+		}
+		members[name] = sorted
+		return name, true, nil
+	}
+
+	accepting := make(map[T]struct{})
+	symbols := make(map[Sym]struct{}, len(syms))
+	transitions := make(map[TransitionKey[T, Sym]]T)
+	for _, sym := range syms {
+		symbols[sym] = struct{}{}
+	}
+	initial := make(map[S]struct{}, len(n.initial))
+	for s := range n.initial {
+		initial[s] = struct{}{}
+	}
+	start, _, err := intern(n.closure(initial))
+	if err != nil {
+		return nil, err
+	}
+
+	queue := []T{start}
+	for i := 0; i < len(queue); i++ {
+		cur := queue[i]
+		for _, s := range members[cur] {
+			if n.Accepting(s) {
+				accepting[cur] = struct{}{}
+				break
+			}
+		}
+		for _, sym := range syms {
+			next := make(map[S]struct{})
+			for _, s := range members[cur] {
+				for to := range n.transitions[TransitionKey[S, Sym]{From: s, Symbol: sym}] {
+					next[to] = struct{}{}
+				}
+			}
+			if len(next) == 0 {
+				continue
+			}
+			name, fresh, err := intern(n.closure(next))
+			if err != nil {
+				return nil, err
+			}
+			transitions[TransitionKey[T, Sym]{From: cur, Symbol: sym}] = name
+			if fresh {
+				queue = append(queue, name)
+			}
+		}
+	}
+	states := make(map[T]struct{}, len(members))
+	for name := range members {
+		states[name] = struct{}{}
+	}
+	return newMachine(start, states, accepting, symbols, transitions), nil
+}
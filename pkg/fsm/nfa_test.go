@@ -0,0 +1,253 @@
+package fsm
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestReverseDeterminized(t *testing.T) {
+	// Strings starting with 'a' that contain a 'b'.
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("C", true)
+	b.On("A", 'a', "B")
+	b.On("B", 'a', "B").On("B", 'b', "C")
+	b.On("C", 'a', "C").On("C", 'b', "C")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+
+	r := m.Reverse()
+	if got := r.InitialStates(); !slices.Equal(got, []string{"C"}) {
+		t.Fatalf("expected reversed initial states [C], got %v", got)
+	}
+	if !r.Accepting("A") || r.Accepting("C") {
+		t.Fatalf("expected only A accepting in the reverse")
+	}
+	if got := r.Targets("C", 'b'); !slices.Equal(got, []string{"B", "C"}) {
+		t.Fatalf("expected reversed targets [B C], got %v", got)
+	}
+
+	d, err := r.Determinize()
+	if err != nil {
+		t.Fatalf("unexpected determinize error: %v", err)
+	}
+	for _, w := range allStrings([]rune{'a', 'b'}, 7) {
+		rev := slices.Clone(w)
+		slices.Reverse(rev)
+		if acceptsOrRejects(m, w) != acceptsOrRejects(d, rev) {
+			t.Fatalf("reverse disagrees on %q", string(w))
+		}
+	}
+}
+
+func TestDeterminizeStateNames(t *testing.T) {
+	b := NewBuilder[int, rune]()
+	b.SetInitial(1)
+	b.AddState(3, true)
+	b.On(1, 'x', 2).On(2, 'x', 3).On(1, 'y', 3)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	d, err := m.Reverse().Determinize()
+	if err != nil {
+		t.Fatalf("unexpected determinize error: %v", err)
+	}
+	if d.InitialState() != "{3}" {
+		t.Fatalf("expected initial {3}, got %v", d.InitialState())
+	}
+	if to, ok := d.GetTransition("{3}", 'x'); !ok || to.String() != "{2}" {
+		t.Fatalf("expected {3} --x--> {2}, got %v", to)
+	}
+	if !d.Accepting("{1}") {
+		t.Fatalf("expected {1} to be accepting")
+	}
+}
+
+func TestNFABuilderEpsilonDeterminize(t *testing.T) {
+	// (ab)* | a*c, with two initial states and epsilon links.
+	b := NewNFABuilder[string, rune]()
+	b.AddInitial("L").AddInitial("R")
+	b.AddState("L", true).AddState("Rend", true)
+	b.On("L", 'a', "L1").On("L1", 'b', "L2").OnEpsilon("L2", "L")
+	b.On("R", 'a', "R").OnEpsilon("R", "R1").On("R1", 'c', "Rend")
+	// Epsilon cycle must not loop forever.
+	b.OnEpsilon("R1", "R2").OnEpsilon("R2", "R1")
+	n, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	d, err := n.Determinize()
+	if err != nil {
+		t.Fatalf("unexpected determinize error: %v", err)
+	}
+	for _, w := range allStrings([]rune{'a', 'b', 'c'}, 6) {
+		_, accepted, err := n.Eval(w)
+		if err != nil {
+			t.Fatalf("unexpected eval error: %v", err)
+		}
+		if accepted != acceptsOrRejects(d, w) {
+			t.Fatalf("determinized machine disagrees with NFA on %q", string(w))
+		}
+	}
+	for w, want := range map[string]bool{"": true, "ab": true, "abab": true, "aac": true, "c": true, "aba": false, "ca": false} {
+		if got := acceptsOrRejects(d, []rune(w)); got != want {
+			t.Fatalf("%q: expected %v, got %v", w, want, got)
+		}
+	}
+}
+
+func TestNFABuilderMultipleTargets(t *testing.T) {
+	b := NewNFABuilder[string, rune]()
+	b.AddInitial("A").AddState("C", true)
+	b.On("A", 'x', "B").On("A", 'x', "C")
+	n, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := n.Targets("A", 'x'); !slices.Equal(got, []string{"B", "C"}) {
+		t.Fatalf("expected targets [B C], got %v", got)
+	}
+	d, err := n.Determinize()
+	if err != nil {
+		t.Fatalf("unexpected determinize error: %v", err)
+	}
+	if to, _ := d.GetTransition(d.InitialState(), 'x'); to != "{B,C}" || !d.Accepting(to) {
+		t.Fatalf("expected accepting {B,C}, got %v", to)
+	}
+}
+
+func TestNFABuilderRequiresInitial(t *testing.T) {
+	b := NewNFABuilder[string, rune]()
+	b.AddState("A", true)
+	if _, err := b.Build(); err == nil {
+		t.Fatalf("expected error when no initial state is set")
+	}
+}
+
+func TestEpsilonClosureCycles(t *testing.T) {
+	b := NewNFABuilder[int, rune]()
+	b.AddInitial(0)
+	b.OnEpsilon(0, 1).OnEpsilon(1, 2).OnEpsilon(2, 0).OnEpsilon(2, 3)
+	b.On(3, 'x', 4)
+	n, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	in := NewSet(1)
+	got := n.EpsilonClosure(in)
+	if !slices.Equal(got.Sorted(), []int{0, 1, 2, 3}) {
+		t.Fatalf("expected closure [0 1 2 3], got %v", got.Sorted())
+	}
+	if len(in) != 1 || !in.Contains(1) {
+		t.Fatalf("argument set must not be modified, got %v", in.Sorted())
+	}
+}
+
+func TestNFAEvalMatchesDeterminized(t *testing.T) {
+	// Inputs over {a,b} whose third symbol from the end is 'a'.
+	b := NewNFABuilder[int, rune]()
+	b.AddInitial(0).AddState(3, true)
+	b.On(0, 'a', 0).On(0, 'b', 0).On(0, 'a', 1)
+	b.On(1, 'a', 2).On(1, 'b', 2).On(2, 'a', 3).On(2, 'b', 3)
+	b.OnEpsilon(3, 3)
+	n, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	d, err := n.Determinize()
+	if err != nil {
+		t.Fatalf("unexpected determinize error: %v", err)
+	}
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 500; i++ {
+		w := make([]rune, rng.Intn(12))
+		for j := range w {
+			w[j] = []rune{'a', 'b'}[rng.Intn(2)]
+		}
+		states, accepted, err := n.Eval(w)
+		if err != nil {
+			t.Fatalf("unexpected eval error: %v", err)
+		}
+		if want := len(w) >= 3 && w[len(w)-3] == 'a'; accepted != want {
+			t.Fatalf("%q: expected %v, got %v (states %v)", string(w), want, accepted, states.Sorted())
+		}
+		if accepted != acceptsOrRejects(d, w) {
+			t.Fatalf("%q: NFA and determinized machine disagree", string(w))
+		}
+	}
+}
+
+func TestNFAEvalUnknownSymbol(t *testing.T) {
+	b := NewNFABuilder[int, rune]()
+	b.AddInitial(0).On(0, 'a', 1)
+	n, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if _, accepted, err := n.Eval([]rune("aa")); err != nil || accepted {
+		t.Fatalf("expected plain rejection when states run out, got %v, err: %v", accepted, err)
+	}
+	var terr *TransitionError[Set[int], rune]
+	if _, _, err := n.Eval([]rune("z")); !errors.As(err, &terr) {
+		t.Fatalf("expected *TransitionError, got %v", err)
+	}
+}
+
+func TestDeterminizeToStateNamer(t *testing.T) {
+	b := NewNFABuilder[string, rune]()
+	b.AddInitial("q0").AddState("q2", true)
+	b.On("q0", 'a', "q0").On("q0", 'a', "q1").On("q1", 'b', "q2")
+	n, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+
+	short := func(members []string) string {
+		name := "D"
+		for _, s := range members {
+			name += s[1:]
+		}
+		return name
+	}
+	d, err := DeterminizeTo(n, WithSubsetNamer(short))
+	if err != nil {
+		t.Fatalf("unexpected determinize error: %v", err)
+	}
+	if d.InitialState() != "D0" {
+		t.Fatalf("expected initial D0, got %v", d.InitialState())
+	}
+	if to, _ := d.GetTransition("D0", 'a'); to != "D01" {
+		t.Fatalf("expected D0 --a--> D01, got %v", to)
+	}
+	_, err = d.Eval([]rune("ba"))
+	if err == nil || err.Error() != "no transition from D0 on 98 at position 0" {
+		t.Fatalf("expected custom name in transition error, got %v", err)
+	}
+
+	// Custom state types are supported too.
+	sized, err := DeterminizeTo(n, WithSubsetNamer(func(members []string) int { return len(members) * 10 }))
+	if err == nil {
+		t.Fatalf("expected collision error, got machine with states %v", sized.States())
+	}
+}
+
+func TestDeterminizeToDefaultNames(t *testing.T) {
+	b := NewNFABuilder[int, rune]()
+	b.AddInitial(1).AddInitial(2)
+	n, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	d, err := DeterminizeTo[string](n)
+	if err != nil || d.InitialState() != "{1,2}" {
+		t.Fatalf("expected default name {1,2}, got %v, err: %v", d, err)
+	}
+	if _, err := DeterminizeTo[int](n); err == nil {
+		t.Fatalf("expected error for non-string state type without namer")
+	}
+}
@@ -0,0 +1,133 @@
+package fsm
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Regular operations on machines. Each operation embeds its operands into an
+// NFA, links them with epsilon transitions, determinizes and minimizes. The
+// resulting machines use int states, so operands never collide on labels.
+
+// partState tags a state with the operand it came from.
+type partState[S comparable] struct {
+	Part  int
+	State S
+}
+
+// embed copies m into n, tagging every state with part. The tagged initial
+// state is returned; accepting states are not copied.
+func embed[S comparable, Sym comparable](n *NFA[partState[S], Sym], m *Machine[S, Sym], part int) partState[S] {
+	tag := func(s S) partState[S] { return partState[S]{Part: part, State: s} }
+	for _, s := range m.States() {
+		n.states[tag(s)] = struct{}{}
+	}
+	for sym := range m.symbols {
+		n.symbols[sym] = struct{}{}
+	}
+	for key, to := range m.transitions {
+		n.addTransition(tag(key.From), key.Symbol, tag(to))
+	}
+	return tag(m.initialState)
+}
+
+// determinizeMinimal turns an NFA into its minimal deterministic machine.
+func determinizeMinimal[S comparable, Sym comparable](n *NFA[S, Sym]) (*Machine[int, Sym], error) {
+	d, err := determinize(n, subsetIDs[S]())
+	if err != nil {
+		return nil, err
+	}
+	return d.Minimize(), nil
+}
+
+// subsetIDs returns a subset namer handing out dense int ids. Members are
+// interned to ints and a subset is keyed by its sorted member ids, so unlike
+// the rendered SetState names, distinct subsets never share an id.
+func subsetIDs[S comparable]() func(members []S) int {
+	ids := make(map[S]int)
+	subsets := make(map[string]int)
+	return func(members []S) int {
+		key := make([]int, len(members))
+		for i, s := range members {
+			id, ok := ids[s]
+			if !ok {
+				id = len(ids)
+				ids[s] = id
+			}
+			key[i] = id
+		}
+		slices.Sort(key)
+		var sb strings.Builder
+		for _, id := range key {
+			sb.WriteString(strconv.Itoa(id))
+			sb.WriteByte(',')
+		}
+		id, ok := subsets[sb.String()]
+		if !ok {
+			id = len(subsets)
+			subsets[sb.String()] = id
+		}
+		return id
+	}
+}
+
+// Concat returns a machine accepting every input uv where a accepts u and b
+// accepts v. The alphabet is the union of both alphabets.
+func Concat[S comparable, Sym comparable](a, b *Machine[S, Sym]) (*Machine[int, Sym], error) {
+	n := newNFA[partState[S], Sym]()
+	startA := embed(n, a, 0)
+	startB := embed(n, b, 1)
+	n.initial[startA] = struct{}{}
+	for s := range a.accepting {
+		n.addEpsilon(partState[S]{Part: 0, State: s}, startB)
+	}
+	for s := range b.accepting {
+		n.accepting[partState[S]{Part: 1, State: s}] = struct{}{}
+	}
+	return determinizeMinimal(n)
+}
+
+// Union returns a machine accepting every input accepted by a or by b.
+func Union[S comparable, Sym comparable](a, b *Machine[S, Sym]) (*Machine[int, Sym], error) {
+	n := newNFA[partState[S], Sym]()
+	n.initial[embed(n, a, 0)] = struct{}{}
+	n.initial[embed(n, b, 1)] = struct{}{}
+	for s := range a.accepting {
+		n.accepting[partState[S]{Part: 0, State: s}] = struct{}{}
+	}
+	for s := range b.accepting {
+		n.accepting[partState[S]{Part: 1, State: s}] = struct{}{}
+	}
+	return determinizeMinimal(n)
+}
+
+// Star returns a machine accepting any concatenation of zero or more inputs
+// accepted by a. The empty input is always accepted.
+func Star[S comparable, Sym comparable](a *Machine[S, Sym]) (*Machine[int, Sym], error) {
+	n := newNFA[partState[S], Sym]()
+	start := embed(n, a, 0)
+	hub := partState[S]{Part: -1}
+	n.states[hub] = struct{}{}
+	n.initial[hub] = struct{}{}
+	n.accepting[hub] = struct{}{}
+	n.addEpsilon(hub, start)
+	for s := range a.accepting {
+		n.addEpsilon(partState[S]{Part: 0, State: s}, hub)
+	}
+	return determinizeMinimal(n)
+}
+
+// Plus returns a machine accepting any concatenation of one or more inputs
+// accepted by a. The empty input is accepted only if a accepts it.
+func Plus[S comparable, Sym comparable](a *Machine[S, Sym]) (*Machine[int, Sym], error) {
+	n := newNFA[partState[S], Sym]()
+	start := embed(n, a, 0)
+	n.initial[start] = struct{}{}
+	for s := range a.accepting {
+		tagged := partState[S]{Part: 0, State: s}
+		n.accepting[tagged] = struct{}{}
+		n.addEpsilon(tagged, start)
+	}
+	return determinizeMinimal(n)
+}
@@ -0,0 +1,211 @@
+package fsm
+
+import "testing"
+
+// wordMachine accepts exactly the given words over runes, using string states
+// named after the prefixes read so far.
+func wordMachine(t *testing.T, words ...string) *Machine[string, rune] {
+	t.Helper()
+	b := NewBuilder[string, rune]()
+	b.SetInitial("")
+	for _, w := range words {
+		rs := []rune(w)
+		for i := range rs {
+			b.On(string(rs[:i]), rs[i], string(rs[:i+1]))
+		}
+		b.AddState(w, true)
+	}
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	return m
+}
+
+func TestConcatStraddlesBoundary(t *testing.T) {
+	// Both operands use overlapping labels ("", "a", "ab").
+	a := wordMachine(t, "a", "ab")
+	b := wordMachine(t, "b", "bc")
+	m, err := Concat(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for w, want := range map[string]bool{
+		"ab": true, "abc": true, "abb": true, "abbc": true,
+		"a": false, "b": false, "abcb": false, "ac": false, "": false,
+	} {
+		if got := acceptsOrRejects(m, []rune(w)); got != want {
+			t.Fatalf("%q: expected %v, got %v", w, want, got)
+		}
+	}
+}
+
+func TestConcatEmptyStringOperand(t *testing.T) {
+	a := wordMachine(t, "", "x")
+	b := wordMachine(t, "y")
+	m, err := Concat(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range allStrings([]rune{'x', 'y'}, 4) {
+		want := string(w) == "y" || string(w) == "xy"
+		if got := acceptsOrRejects(m, w); got != want {
+			t.Fatalf("%q: expected %v, got %v", string(w), want, got)
+		}
+	}
+}
+
+func TestConcatBruteForce(t *testing.T) {
+	a := buildMod3(t)
+	b := wordMachine(t, "1", "10")
+	m, err := Concat(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range allStrings([]rune{'0', '1'}, 7) {
+		s := string(w)
+		want := false
+		for i := 0; i <= len(s); i++ {
+			if acceptsOrRejects(a, []rune(s[:i])) && acceptsOrRejects(b, []rune(s[i:])) {
+				want = true
+			}
+		}
+		if got := acceptsOrRejects(m, w); got != want {
+			t.Fatalf("%q: expected %v, got %v", s, want, got)
+		}
+	}
+}
+
+// inStar reports whether s splits into one or more non-empty pieces accepted by
+// in; the empty string is handled by the caller.
+func inStar(s string, in func(string) bool) bool {
+	if s == "" {
+		return true
+	}
+	for i := 1; i <= len(s); i++ {
+		if in(s[:i]) && inStar(s[i:], in) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUnionStarPlusBruteForce(t *testing.T) {
+	a := wordMachine(t, "ab", "b")
+	b := wordMachine(t, "ba")
+	inA := func(s string) bool { return acceptsOrRejects(a, []rune(s)) }
+	inB := func(s string) bool { return acceptsOrRejects(b, []rune(s)) }
+
+	u, err := Union(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	star, err := Star(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plus, err := Plus(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	starUnion, err := Star(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, w := range allStrings([]rune{'a', 'b'}, 7) {
+		s := string(w)
+		inU := func(s string) bool { return inA(s) || inB(s) }
+		checks := []struct {
+			name string
+			m    *Machine[int, rune]
+			want bool
+		}{
+			{"Union", u, inU(s)},
+			{"Star", star, inStar(s, inA)},
+			{"Plus", plus, s != "" && inStar(s, inA)},
+			{"Star(Union)", starUnion, inStar(s, inU)},
+		}
+		for _, c := range checks {
+			if got := acceptsOrRejects(c.m, w); got != c.want {
+				t.Fatalf("%s on %q: expected %v, got %v", c.name, s, c.want, got)
+			}
+		}
+	}
+}
+
+func TestStarAndPlusEmptyString(t *testing.T) {
+	a := wordMachine(t, "x")
+	star, err := Star(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plus, err := Plus(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !acceptsOrRejects(star, nil) {
+		t.Fatalf("Star must accept the empty string")
+	}
+	if acceptsOrRejects(plus, nil) {
+		t.Fatalf("Plus must not accept the empty string when the operand does not")
+	}
+	plusEps, err := Plus(wordMachine(t, "", "x"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !acceptsOrRejects(plusEps, nil) {
+		t.Fatalf("Plus must accept the empty string when the operand does")
+	}
+}
+
+func TestOpsSubsetsWithAmbiguousRenderings(t *testing.T) {
+	// The subsets {p, q} of Union's two operands and {"p},{1 q"} of the first
+	// operand render alike under %v.
+	ba := NewBuilder[string, rune]()
+	ba.SetInitial("p").On("p", 'x', "p},{1 q").AddState("p},{1 q", true)
+	a, err := ba.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	bb := NewBuilder[string, rune]()
+	bb.SetInitial("q").On("q", 'y', "q").AddState("q", true)
+	b, err := bb.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	u, err := Union(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for w, want := range map[string]bool{"": true, "x": true, "yy": true, "xx": false, "xy": false} {
+		if got := acceptsOrRejects(u, []rune(w)); got != want {
+			t.Fatalf("union %q: expected %v, got %v", w, want, got)
+		}
+	}
+
+	// The states 1 and "1" render alike under %v.
+	bm := NewBuilder[any, rune]()
+	bm.SetInitial(1).On(1, 'x', "1").AddState("1", true)
+	m, err := bm.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	for name, tc := range map[string]struct {
+		op     func() (*Machine[int, rune], error)
+		wantXX bool
+	}{
+		"union":  {func() (*Machine[int, rune], error) { return Union(m, m) }, false},
+		"concat": {func() (*Machine[int, rune], error) { return Concat(m, m) }, true},
+		"star":   {func() (*Machine[int, rune], error) { return Star(m) }, true},
+		"plus":   {func() (*Machine[int, rune], error) { return Plus(m) }, true},
+	} {
+		got, err := tc.op()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if acceptsOrRejects(got, []rune("x")) != (name != "concat") || acceptsOrRejects(got, []rune("xx")) != tc.wantXX {
+			t.Fatalf("%s: unexpected verdicts on x and xx", name)
+		}
+	}
+}
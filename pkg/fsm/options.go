@@ -4,10 +4,30 @@ package fsm
 
 type buildOptions struct {
 	preventOverwriteTransitions bool
+	collectOverwriteErrors       bool
+	errorOnOverwriteTransitions  bool
+	strictRegistration           bool
+	eagerValidation              bool
+	errorOnUnusedSymbols         bool
+	errorOnDeadStates            bool
+	maxValidationErrors          int
+	allowEmptyAlphabet           bool
+	requireInitialOutgoing       bool
+	warningsAsErrors             bool
+	stateNamer                   func(any) string
+	symbolNamer                  func(any) string
+	mutableAfterBuild            bool
+	completeWithSink             bool
+	completeSink                 any
 	requireTotalTransitions      bool
 	requireAtLeastOneAccepting   bool
 	errorOnUnreachableStates     bool
 	errorWhenNoAcceptingReachable bool
+	errorOnDeadTransitions        bool
+	requireAllStatesUseful        bool
+	errorOnTerminalStates         bool
+	terminalStatesAllowed         map[any]struct{}
+	stateLess                     any
 }
 
 // Option mutates buildOptions when constructing a Builder.
@@ -18,6 +38,52 @@ func WithPreventOverwriteTransitions() Option {
 	return func(o *buildOptions) { o.preventOverwriteTransitions = true }
 }
 
+// WithCollectOverwriteErrors makes a transition defined twice keep its first
+// target and fail Build with a DuplicateTransitionError instead of being
+// overwritten. It takes precedence over WithPreventOverwriteTransitions, so On
+// never panics.
+func WithCollectOverwriteErrors() Option {
+	return func(o *buildOptions) { o.collectOverwriteErrors = true }
+}
+
+// WithErrorOnOverwriteTransitions makes On keep the first target of a
+// transition defined twice and record every redefinition, with its old and new
+// target, as a DuplicateTransitionError reported by Build. It cannot be
+// combined with WithPreventOverwriteTransitions; NewBuilder panics if both are
+// given.
+func WithErrorOnOverwriteTransitions() Option {
+	return func(o *buildOptions) { o.errorOnOverwriteTransitions = true }
+}
+
+// WithStrictRegistration catches typos hidden by implicit registration: a
+// state or symbol referenced by On, SetInitial, OnElse or From before being
+// added with AddState, AddSymbol or their variants fails Build with an error
+// naming the call.
+func WithStrictRegistration() Option {
+	return func(o *buildOptions) { o.strictRegistration = true }
+}
+
+// WithEagerValidation reports definition mistakes known under the other
+// options as soon as they are made, through Builder.PendingErrors, instead of
+// panicking: a transition redefined under WithPreventOverwriteTransitions keeps
+// its first target and records a DuplicateTransitionError. Strict-registration
+// violations are always recorded when made. Each check is O(1) per call.
+func WithEagerValidation() Option {
+	return func(o *buildOptions) { o.eagerValidation = true }
+}
+
+// WithCompleteWithSink makes Build add sink as a non-accepting state looping
+// on every symbol and route every missing transition to it, so the machine is
+// total. The sink and the transitions into it are exempt from the
+// reachability, usefulness and dead-transition checks. The sink must have the
+// builder's state type; otherwise Build fails.
+func WithCompleteWithSink[S comparable](sink S) Option {
+	return func(o *buildOptions) {
+		o.completeWithSink = true
+		o.completeSink = sink
+	}
+}
+
 // WithRequireTotalTransitions enforces that δ is total: every (state, symbol) has a transition.
 func WithRequireTotalTransitions() Option {
 	return func(o *buildOptions) { o.requireTotalTransitions = true }
@@ -39,3 +105,171 @@ func WithErrorWhenNoAcceptingReachable() Option {
 }
 
 
+
+// WithErrorOnDeadTransitions fails build if any transition can never occur on an accepting run.
+func WithErrorOnDeadTransitions() Option {
+	return func(o *buildOptions) { o.errorOnDeadTransitions = true }
+}
+
+// WithErrorOnDeadStates fails build for every state reachable from q0 that cannot reach F.
+// Unreachable states are left to WithErrorOnUnreachableStates.
+func WithErrorOnDeadStates() Option {
+	return func(o *buildOptions) { o.errorOnDeadStates = true }
+}
+
+// WithRequireAllStatesUseful fails build for every state that is unreachable from q0 or cannot reach F.
+func WithRequireAllStatesUseful() Option {
+	return func(o *buildOptions) { o.requireAllStatesUseful = true }
+}
+
+// WithErrorOnTerminalStates fails build for every state without outgoing transitions, except the listed ones.
+// The exceptions must have the builder's state type; otherwise Build fails.
+func WithErrorOnTerminalStates[S comparable](except ...S) Option {
+	return func(o *buildOptions) {
+		o.errorOnTerminalStates = true
+		if o.terminalStatesAllowed == nil {
+			o.terminalStatesAllowed = make(map[any]struct{})
+		}
+		for _, s := range except {
+			o.terminalStatesAllowed[s] = struct{}{}
+		}
+	}
+}
+
+// WithRequireInitialOutgoing fails build if q0 has no outgoing transition,
+// counting those added by OnElse and WithCompleteWithSink.
+func WithRequireInitialOutgoing() Option {
+	return func(o *buildOptions) { o.requireInitialOutgoing = true }
+}
+
+// WithErrorOnUnusedSymbols fails build for every symbol without a transition,
+// counting those added by OnElse but not those into a WithCompleteWithSink sink.
+func WithErrorOnUnusedSymbols() Option {
+	return func(o *buildOptions) { o.errorOnUnusedSymbols = true }
+}
+
+// WithMaxValidationErrors makes Build keep only the first n errors, followed by
+// one reporting how many more were found. n <= 0 means no limit, the default.
+func WithMaxValidationErrors(n int) Option {
+	return func(o *buildOptions) { o.maxValidationErrors = n }
+}
+
+// WithAllowEmptyAlphabet lets build succeed without input symbols. The machine
+// then only reads the empty input, accepted iff q0 is accepting.
+func WithAllowEmptyAlphabet() Option {
+	return func(o *buildOptions) { o.allowEmptyAlphabet = true }
+}
+
+// WithWarningsAsErrors makes every BuildReport warning fail build.
+func WithWarningsAsErrors() Option {
+	return func(o *buildOptions) { o.warningsAsErrors = true }
+}
+
+// WithStateNamer renders states with namer instead of %v in build errors,
+// warnings and the built machine's TransitionErrors. A panicking namer falls
+// back to %v.
+func WithStateNamer(namer func(any) string) Option {
+	return func(o *buildOptions) { o.stateNamer = namer }
+}
+
+// WithSymbolNamer is WithStateNamer for symbols.
+func WithSymbolNamer(namer func(any) string) Option {
+	return func(o *buildOptions) { o.symbolNamer = namer }
+}
+
+// WithMutableAfterBuild keeps the builder mutable after a successful Build
+// instead of freezing it. Machines already built never see later changes.
+func WithMutableAfterBuild() Option {
+	return func(o *buildOptions) { o.mutableAfterBuild = true }
+}
+
+// WithStateLess orders the built machine's States after the initial state by less instead of the default order.
+// It lets textual output be reproducible and meaningful for state types without a natural order.
+// The function must have the builder's state type; otherwise Build fails.
+func WithStateLess[S comparable](less func(a, b S) bool) Option {
+	return func(o *buildOptions) { o.stateLess = less }
+}
+
+// BuildOptionsInfo records which builder options a machine was built with, so
+// later consumers can rely on the checks Build already enforced. The JSON tags
+// let it travel as metadata next to a serialized machine.
+type BuildOptionsInfo struct {
+	PreventOverwriteTransitions   bool `json:"preventOverwriteTransitions,omitempty"`
+	CollectOverwriteErrors        bool `json:"collectOverwriteErrors,omitempty"`
+	ErrorOnOverwriteTransitions   bool `json:"errorOnOverwriteTransitions,omitempty"`
+	StrictRegistration            bool `json:"strictRegistration,omitempty"`
+	EagerValidation               bool `json:"eagerValidation,omitempty"`
+	CompleteWithSink              bool `json:"completeWithSink,omitempty"`
+	RequireTotalTransitions       bool `json:"requireTotalTransitions,omitempty"`
+	RequireAtLeastOneAccepting    bool `json:"requireAtLeastOneAccepting,omitempty"`
+	ErrorOnUnreachableStates      bool `json:"errorOnUnreachableStates,omitempty"`
+	ErrorWhenNoAcceptingReachable bool `json:"errorWhenNoAcceptingReachable,omitempty"`
+	ErrorOnDeadTransitions        bool `json:"errorOnDeadTransitions,omitempty"`
+	ErrorOnDeadStates             bool `json:"errorOnDeadStates,omitempty"`
+	RequireAllStatesUseful        bool `json:"requireAllStatesUseful,omitempty"`
+	ErrorOnTerminalStates         bool `json:"errorOnTerminalStates,omitempty"`
+	RequireInitialOutgoing        bool `json:"requireInitialOutgoing,omitempty"`
+	ErrorOnUnusedSymbols          bool `json:"errorOnUnusedSymbols,omitempty"`
+	AllowEmptyAlphabet            bool `json:"allowEmptyAlphabet,omitempty"`
+	WarningsAsErrors              bool `json:"warningsAsErrors,omitempty"`
+	MaxValidationErrors           int  `json:"maxValidationErrors,omitempty"`
+}
+
+// info returns the BuildOptionsInfo mirroring o.
+func (o *buildOptions) info() BuildOptionsInfo {
+	return BuildOptionsInfo{
+		PreventOverwriteTransitions:   o.preventOverwriteTransitions,
+		CollectOverwriteErrors:        o.collectOverwriteErrors,
+		ErrorOnOverwriteTransitions:   o.errorOnOverwriteTransitions,
+		StrictRegistration:            o.strictRegistration,
+		EagerValidation:               o.eagerValidation,
+		CompleteWithSink:              o.completeWithSink,
+		RequireTotalTransitions:       o.requireTotalTransitions,
+		RequireAtLeastOneAccepting:    o.requireAtLeastOneAccepting,
+		ErrorOnUnreachableStates:      o.errorOnUnreachableStates,
+		ErrorWhenNoAcceptingReachable: o.errorWhenNoAcceptingReachable,
+		ErrorOnDeadTransitions:        o.errorOnDeadTransitions,
+		ErrorOnDeadStates:             o.errorOnDeadStates,
+		RequireAllStatesUseful:        o.requireAllStatesUseful,
+		ErrorOnTerminalStates:         o.errorOnTerminalStates,
+		RequireInitialOutgoing:        o.requireInitialOutgoing,
+		ErrorOnUnusedSymbols:          o.errorOnUnusedSymbols,
+		AllowEmptyAlphabet:            o.allowEmptyAlphabet,
+		WarningsAsErrors:              o.warningsAsErrors,
+		MaxValidationErrors:           o.maxValidationErrors,
+	}
+}
+
+// ConflictPolicy decides what happens when a transition being added already
+// exists in a builder with a different target.
+type ConflictPolicy int
+
+const (
+	// ConflictOverwrite replaces the existing target. Under
+	// WithPreventOverwriteTransitions, WithCollectOverwriteErrors or
+	// WithErrorOnOverwriteTransitions it is reported as a conflict instead,
+	// like ConflictError.
+	ConflictOverwrite ConflictPolicy = iota
+	// ConflictKeep keeps the existing target.
+	ConflictKeep
+	// ConflictError keeps the existing target and makes Build fail.
+	ConflictError
+)
+
+type importOptions struct {
+	conflict      ConflictPolicy
+	importInitial bool
+}
+
+// ImportOption configures Builder.ImportMachine.
+type ImportOption func(*importOptions)
+
+// WithImportConflict sets how conflicting transitions are resolved; the default is ConflictOverwrite.
+func WithImportConflict(policy ConflictPolicy) ImportOption {
+	return func(o *importOptions) { o.conflict = policy }
+}
+
+// WithImportInitial makes the imported machine's initial state the builder's initial state.
+func WithImportInitial() ImportOption {
+	return func(o *importOptions) { o.importInitial = true }
+}
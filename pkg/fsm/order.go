@@ -0,0 +1,55 @@
+package fsm
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// compareValues imposes a deterministic total order on arbitrary comparable values.
+// Values of ordered kinds (integers, floats, strings, bools) compare naturally,
+// everything else falls back to comparing the formatted representation.
+func compareValues[T any](a, b T) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsValid() && vb.IsValid() && va.Kind() == vb.Kind() {
+		switch va.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(va.Int(), vb.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cmp.Compare(va.Uint(), vb.Uint())
+		case reflect.Float32, reflect.Float64:
+			return cmp.Compare(va.Float(), vb.Float())
+		case reflect.String:
+			return cmp.Compare(va.String(), vb.String())
+		case reflect.Bool:
+			switch {
+			case va.Bool() == vb.Bool():
+				return 0
+			case vb.Bool():
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	if c := cmp.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(fmt.Sprintf("%#v", a), fmt.Sprintf("%#v", b))
+}
+
+// sortedKeys returns the keys of a set or map in deterministic order.
+func sortedKeys[T comparable, V any](set map[T]V) []T {
+	out := make([]T, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	slices.SortFunc(out, compareValues[T])
+	return out
+}
+
+// sortedSymbols returns the machine's alphabet in deterministic order.
+func (m *Machine[S, Sym]) sortedSymbols() []Sym {
+	return sortedKeys(m.symbols)
+}
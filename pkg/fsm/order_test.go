@@ -0,0 +1,36 @@
+package fsm
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareValuesOrderedKinds(t *testing.T) {
+	if compareValues(2, 10) >= 0 {
+		t.Fatalf("expected numeric comparison for ints")
+	}
+	if compareValues("b", "a") <= 0 {
+		t.Fatalf("expected lexical comparison for strings")
+	}
+	if compareValues(false, true) >= 0 {
+		t.Fatalf("expected false < true")
+	}
+	type named int
+	if compareValues(named(3), named(-1)) <= 0 {
+		t.Fatalf("expected named integer types to compare numerically")
+	}
+}
+
+func TestSortedKeysStructValues(t *testing.T) {
+	type pair struct {
+		A int
+		B string
+	}
+	set := map[pair]struct{}{{2, "x"}: {}, {1, "y"}: {}, {1, "x"}: {}}
+	want := []pair{{1, "x"}, {1, "y"}, {2, "x"}}
+	for i := 0; i < 10; i++ {
+		if got := sortedKeys(set); !slices.Equal(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
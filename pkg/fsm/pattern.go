@@ -0,0 +1,75 @@
+package fsm
+
+import "strings"
+
+// PatternBuilder defines a reusable sub-machine inside a string-state
+// Builder. Every state name it is given is prefixed with the namespace passed
+// to ApplyPattern, so instances of a pattern never share states.
+type PatternBuilder[Sym comparable] struct {
+	b           *Builder[string, Sym]
+	prefix      string
+	entry, exit string
+	hasEntry    bool
+	hasExit     bool
+	// invalid holds the names already reported for containing a dot.
+	invalid map[string]struct{}
+}
+
+// State returns the namespaced name of the pattern state name. A name
+// containing "." could collide with another namespace, so it makes Build
+// fail.
+func (p *PatternBuilder[Sym]) State(name string) string {
+	if _, seen := p.invalid[name]; !seen && strings.Contains(name, ".") {
+		p.invalid[name] = struct{}{}
+		p.b.pending = append(p.b.pending, newBuildError(KindInvalidDefinition, "pattern %s: state name %q contains \".\"", p.prefix, name))
+	}
+	return p.prefix + "." + name
+}
+
+// AddState is Builder.AddState on the namespaced state.
+func (p *PatternBuilder[Sym]) AddState(name string, isAccepting bool) *PatternBuilder[Sym] {
+	p.b.AddState(p.State(name), isAccepting)
+	return p
+}
+
+// On is Builder.On between namespaced states.
+func (p *PatternBuilder[Sym]) On(from string, sym Sym, to string) *PatternBuilder[Sym] {
+	p.b.On(p.State(from), sym, p.State(to))
+	return p
+}
+
+// SetEntry designates the state the outer machine enters the pattern through.
+func (p *PatternBuilder[Sym]) SetEntry(name string) *PatternBuilder[Sym] {
+	p.entry, p.hasEntry = p.State(name), true
+	p.b.checkRegistered([]string{p.entry}, nil, "SetEntry(%v)", p.b.stateName(p.entry))
+	p.b.registerImplicit(p.entry)
+	return p
+}
+
+// SetExit designates the state the outer machine leaves the pattern from.
+func (p *PatternBuilder[Sym]) SetExit(name string) *PatternBuilder[Sym] {
+	p.exit, p.hasExit = p.State(name), true
+	p.b.checkRegistered([]string{p.exit}, nil, "SetExit(%v)", p.b.stateName(p.exit))
+	p.b.registerImplicit(p.exit)
+	return p
+}
+
+// ApplyPattern instantiates pattern in b under the namespace prefix, naming
+// each pattern state prefix + "." + name, and returns the namespaced entry and
+// exit states for wiring the instance into the rest of the machine. A pattern
+// that sets no entry or no exit, or a prefix containing ".", makes Build fail.
+func ApplyPattern[Sym comparable](b *Builder[string, Sym], prefix string, pattern func(p *PatternBuilder[Sym])) (entry, exit string) {
+	b.checkFrozen()
+	if strings.Contains(prefix, ".") {
+		b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "pattern prefix %q contains \".\"", prefix))
+	}
+	p := &PatternBuilder[Sym]{b: b, prefix: prefix, invalid: map[string]struct{}{}}
+	pattern(p)
+	if !p.hasEntry {
+		b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "pattern %s has no entry state", prefix))
+	}
+	if !p.hasExit {
+		b.pending = append(b.pending, newBuildError(KindInvalidDefinition, "pattern %s has no exit state", prefix))
+	}
+	return p.entry, p.exit
+}
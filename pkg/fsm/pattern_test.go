@@ -0,0 +1,105 @@
+package fsm
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// retry fails up to three times on 'f' before giving up, and leaves on 'o'.
+func retry(p *PatternBuilder[rune]) {
+	p.AddState("try1", false).AddState("try2", false).AddState("try3", false)
+	p.On("try1", 'f', "try2").On("try2", 'f', "try3").On("try3", 'f', "failed")
+	for _, s := range []string{"try1", "try2", "try3"} {
+		p.On(s, 'o', "done")
+	}
+	p.SetEntry("try1").SetExit("done")
+}
+
+func TestApplyPatternInstancesAreDisjoint(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("start").AddState("end", true)
+	in1, out1 := ApplyPattern(b, "fetch", retry)
+	in2, out2 := ApplyPattern(b, "store", retry)
+	if in1 != "fetch.try1" || out2 != "store.done" {
+		t.Fatalf("expected namespaced handles, got %s %s", in1, out2)
+	}
+	b.On("start", 's', in1).On(out1, 's', in2).On(out2, 's', "end")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	for _, s := range m.States() {
+		if s != "start" && s != "end" && !strings.HasPrefix(s, "fetch.") && !strings.HasPrefix(s, "store.") {
+			t.Fatalf("unexpected state %s", s)
+		}
+	}
+	if len(m.States()) != 2+2*5 {
+		t.Fatalf("expected two disjoint five-state instances, got %v", m.States())
+	}
+	// Failures in the first instance must not count against the second.
+	for input, want := range map[string]string{
+		"so":        "fetch.done",
+		"sfff":      "fetch.failed",
+		"sffos":     "store.try1",
+		"sffosff":   "store.try3",
+		"sffosffos": "end",
+	} {
+		if got, err := m.Eval([]rune(input)); err != nil || got != want {
+			t.Errorf("%s: want %s, got %v, %v", input, want, got, err)
+		}
+	}
+}
+
+func TestApplyPatternRequiresEntryAndExit(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("start").AddState("start", true).SelfLoop("start", 'x')
+	entry, exit := ApplyPattern(b, "p", func(p *PatternBuilder[rune]) {
+		p.On("a", 'x', "b")
+	})
+	if entry != "" || exit != "" {
+		t.Fatalf("expected empty handles, got %q %q", entry, exit)
+	}
+	_, err := b.Build()
+	for _, want := range []string{"pattern p has no entry state", "pattern p has no exit state"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+	if !slices.Contains(b.States(), "p.a") {
+		t.Fatalf("expected pattern states to be namespaced, got %v", b.States())
+	}
+}
+
+func TestApplyPatternRejectsDots(t *testing.T) {
+	// Without the check, "a" + "." + "b.c" and "a.b" + "." + "c" would share
+	// the state "a.b.c".
+	b := NewBuilder[string, rune]()
+	b.SetInitial("start").AddState("start", true).SelfLoop("start", 'x')
+	ApplyPattern(b, "a", func(p *PatternBuilder[rune]) {
+		p.On("b.c", 'x', "b.c").On("b.c", 'y', "b.c").SetEntry("b.c").SetExit("b.c")
+	})
+	ApplyPattern(b, "a.b", func(p *PatternBuilder[rune]) {
+		p.SetEntry("c").SetExit("c")
+	})
+	_, err := b.Build()
+	var verr *ValidationErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	var invalid int
+	for _, e := range verr.Errors() {
+		if be, ok := e.(*BuildError); ok && be.Kind == KindInvalidDefinition {
+			invalid++
+		}
+	}
+	if invalid != 2 {
+		t.Fatalf("expected one error for the name and one for the prefix, got %v", err)
+	}
+	for _, want := range []string{`pattern a: state name "b.c" contains "."`, `pattern prefix "a.b" contains "."`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+// Package regexfsm compiles regular expressions into minimal deterministic
+// machines from package fsm.
+//
+// The supported syntax is a small, always-anchored subset of Go's regexp:
+// literals, concatenation, alternation (|), grouping with ( ) or (?: ),
+// the repetition operators *, + and ?, character classes such as [a-z0-9],
+// the class escapes \d, \w and \s, and escaping of metacharacters with \.
+// The alphabet of the compiled machine is the set of runes mentioned in the
+// pattern; any other input rune has no transition.
+package regexfsm
+
+import (
+	"fmt"
+
+	"github.com/bohdan-natsevych/fsm-generator/pkg/fsm"
+)
+
+// ParseError reports a syntax error or an unsupported construct in a pattern.
+type ParseError struct {
+	Pos int // rune offset into the pattern
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("regexfsm: %s at position %d", e.Msg, e.Pos)
+}
+
+// Compile parses pattern and returns the minimal machine accepting exactly the
+// strings matched by the whole pattern.
+func Compile(pattern string) (*fsm.Machine[int, rune], error) {
+	p := &parser{src: []rune(pattern), nfa: fsm.NewNFABuilder[int, rune]()}
+	frag, err := p.parseAlt()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		// Only an unbalanced ')' stops the top-level alternation early.
+		return nil, &ParseError{Pos: p.pos, Msg: "unexpected ')'"}
+	}
+	p.nfa.AddInitial(frag.start).AddState(frag.end, true)
+	n, err := p.nfa.Build()
+	if err != nil {
+		return nil, err
+	}
+	d, err := n.Determinize()
+	if err != nil {
+		return nil, err
+	}
+	return d.Minimize(), nil
+}
+
+// MustCompile is like Compile but panics on error.
+func MustCompile(pattern string) *fsm.Machine[int, rune] {
+	m, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// fragment is a piece of the Thompson construction with one entry and one exit.
+type fragment struct {
+	start, end int
+}
+
+type parser struct {
+	src  []rune
+	pos  int
+	next int
+	nfa  *fsm.NFABuilder[int, rune]
+}
+
+func (p *parser) newState() int {
+	s := p.next
+	p.next++
+	p.nfa.AddState(s, false)
+	return s
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) error {
+	return &ParseError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) peek() (rune, bool) {
+	if p.pos >= len(p.src) {
+		return 0, false
+	}
+	return p.src[p.pos], true
+}
+
+// parseAlt parses concat ('|' concat)*.
+func (p *parser) parseAlt() (fragment, error) {
+	left, err := p.parseConcat()
+	if err != nil {
+		return fragment{}, err
+	}
+	for {
+		r, ok := p.peek()
+		if !ok || r != '|' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseConcat()
+		if err != nil {
+			return fragment{}, err
+		}
+		f := fragment{start: p.newState(), end: p.newState()}
+		p.nfa.OnEpsilon(f.start, left.start).OnEpsilon(f.start, right.start)
+		p.nfa.OnEpsilon(left.end, f.end).OnEpsilon(right.end, f.end)
+		left = f
+	}
+}
+
+// parseConcat parses a possibly empty sequence of repeated atoms.
+func (p *parser) parseConcat() (fragment, error) {
+	s := p.newState()
+	f := fragment{start: s, end: s}
+	for {
+		r, ok := p.peek()
+		if !ok || r == '|' || r == ')' {
+			return f, nil
+		}
+		next, err := p.parseRepeat()
+		if err != nil {
+			return fragment{}, err
+		}
+		p.nfa.OnEpsilon(f.end, next.start)
+		f.end = next.end
+	}
+}
+
+// parseRepeat parses an atom followed by any number of *, + or ? operators.
+func (p *parser) parseRepeat() (fragment, error) {
+	f, err := p.parseAtom()
+	if err != nil {
+		return fragment{}, err
+	}
+	for {
+		r, ok := p.peek()
+		if !ok {
+			return f, nil
+		}
+		switch r {
+		case '*', '+', '?':
+			p.pos++
+			g := fragment{start: p.newState(), end: p.newState()}
+			p.nfa.OnEpsilon(g.start, f.start).OnEpsilon(f.end, g.end)
+			if r != '+' {
+				p.nfa.OnEpsilon(g.start, g.end)
+			}
+			if r != '?' {
+				p.nfa.OnEpsilon(f.end, f.start)
+			}
+			f = g
+		case '{':
+			return fragment{}, p.errorf(p.pos, "counted repetition is not supported")
+		default:
+			return f, nil
+		}
+	}
+}
+
+// parseAtom parses a literal, escape, class or group.
+func (p *parser) parseAtom() (fragment, error) {
+	start := p.pos
+	r := p.src[p.pos]
+	switch r {
+	case '(':
+		p.pos++
+		if r, ok := p.peek(); ok && r == '?' {
+			if p.pos+1 < len(p.src) && p.src[p.pos+1] == ':' {
+				p.pos += 2
+			} else {
+				return fragment{}, p.errorf(start, "lookaround and group flags are not supported")
+			}
+		}
+		f, err := p.parseAlt()
+		if err != nil {
+			return fragment{}, err
+		}
+		if r, ok := p.peek(); !ok || r != ')' {
+			return fragment{}, p.errorf(start, "missing ')'")
+		}
+		p.pos++
+		return f, nil
+	case '[':
+		set, err := p.parseClass()
+		if err != nil {
+			return fragment{}, err
+		}
+		return p.runeSet(set), nil
+	case '\\':
+		set, err := p.parseEscape()
+		if err != nil {
+			return fragment{}, err
+		}
+		return p.runeSet(set), nil
+	case '*', '+', '?':
+		return fragment{}, p.errorf(start, "missing argument to repetition operator %q", r)
+	case '.', '^', '$':
+		return fragment{}, p.errorf(start, "%q is not supported", r)
+	}
+	p.pos++
+	return p.runeSet([]rune{r}), nil
+}
+
+// runeSet builds a fragment accepting exactly one rune from set.
+func (p *parser) runeSet(set []rune) fragment {
+	f := fragment{start: p.newState(), end: p.newState()}
+	for _, r := range set {
+		p.nfa.On(f.start, r, f.end)
+	}
+	return f
+}
+
+var classEscapes = map[rune][]rune{
+	'd': runeRange('0', '9'),
+	'w': append(append(append(runeRange('0', '9'), runeRange('A', 'Z')...), runeRange('a', 'z')...), '_'),
+	's': {'\t', '\n', '\f', '\r', ' '},
+}
+
+func runeRange(lo, hi rune) []rune {
+	out := make([]rune, 0, hi-lo+1)
+	for r := lo; r <= hi; r++ {
+		out = append(out, r)
+	}
+	return out
+}
+
+// parseEscape parses a backslash escape at p.pos and returns the runes it denotes.
+func (p *parser) parseEscape() ([]rune, error) {
+	start := p.pos
+	p.pos++
+	r, ok := p.peek()
+	if !ok {
+		return nil, p.errorf(start, "trailing backslash")
+	}
+	p.pos++
+	if set, ok := classEscapes[r]; ok {
+		return set, nil
+	}
+	switch {
+	case r >= '1' && r <= '9':
+		return nil, p.errorf(start, "backreferences are not supported")
+	case r == 'n':
+		return []rune{'\n'}, nil
+	case r == 't':
+		return []rune{'\t'}, nil
+	case r == 'r':
+		return []rune{'\r'}, nil
+	case r < 0x80 && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'):
+		return []rune{r}, nil
+	}
+	return nil, p.errorf(start, "unsupported escape \\%c", r)
+}
+
+// parseClass parses a bracketed character class at p.pos.
+func (p *parser) parseClass() ([]rune, error) {
+	start := p.pos
+	p.pos++
+	if r, ok := p.peek(); ok && r == '^' {
+		return nil, p.errorf(start, "negated character classes are not supported")
+	}
+	var set []rune
+	first := true
+	for {
+		r, ok := p.peek()
+		if !ok {
+			return nil, p.errorf(start, "missing ']'")
+		}
+		if r == ']' && !first {
+			p.pos++
+			return set, nil
+		}
+		first = false
+		var lo rune
+		if r == '\\' {
+			esc, err := p.parseEscape()
+			if err != nil {
+				return nil, err
+			}
+			if len(esc) != 1 {
+				set = append(set, esc...)
+				continue
+			}
+			lo = esc[0]
+		} else {
+			lo = r
+			p.pos++
+		}
+		if p.pos+1 < len(p.src) && p.src[p.pos] == '-' && p.src[p.pos+1] != ']' {
+			rangePos := p.pos
+			p.pos++
+			hi := p.src[p.pos]
+			if hi == '\\' {
+				esc, err := p.parseEscape()
+				if err != nil {
+					return nil, err
+				}
+				if len(esc) != 1 {
+					return nil, p.errorf(rangePos, "invalid class range")
+				}
+				hi = esc[0]
+			} else {
+				p.pos++
+			}
+			if hi < lo {
+				return nil, p.errorf(rangePos, "invalid class range %c-%c", lo, hi)
+			}
+			set = append(set, runeRange(lo, hi)...)
+			continue
+		}
+		set = append(set, lo)
+	}
+}
@@ -0,0 +1,128 @@
+package regexfsm
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func accepts(t *testing.T, pattern, in string) bool {
+	t.Helper()
+	m, err := Compile(pattern)
+	if err != nil {
+		t.Fatalf("compile %q: %v", pattern, err)
+	}
+	ok, err := m.EvalAccepting([]rune(in))
+	return err == nil && ok
+}
+
+func TestCompileMatchesGoRegexp(t *testing.T) {
+	patterns := []string{
+		"",
+		"abc",
+		"a|b|",
+		"(ab)*",
+		"a(b|c)+d?",
+		"[a-c0-2]*x",
+		"(?:a|bc)*c",
+		"\\d+(\\.\\d+)?",
+		"[a\\-z]+",
+		"(a*)*b",
+		"\\w\\s?",
+	}
+	corpus := []string{
+		"", "a", "b", "c", "d", "x", "ab", "abab", "aba", "abc", "abd", "acd",
+		"abbcd", "ad", "a0x", "cc2x", "3x", "x", "bcc", "abcc", "ac", "c",
+		"12", "1.5", "1.", ".5", "-", "a-z", "zz-", "aaab", "b", "ba",
+		"_", "_ ", "Z\t", "  ",
+	}
+	for _, pattern := range patterns {
+		m, err := Compile(pattern)
+		if err != nil {
+			t.Fatalf("compile %q: %v", pattern, err)
+		}
+		re := regexp.MustCompile("^(?:" + pattern + ")$")
+		for _, in := range corpus {
+			got, err := m.EvalAccepting([]rune(in))
+			got = got && err == nil
+			if want := re.MatchString(in); got != want {
+				t.Fatalf("pattern %q on %q: want %v, got %v", pattern, in, want, got)
+			}
+		}
+	}
+}
+
+func TestCompileIsMinimal(t *testing.T) {
+	// (a|b)*abb has a well-known four-state minimal DFA.
+	m, err := Compile("(a|b)*abb")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(m.States()); n != 4 {
+		t.Fatalf("expected 4 states, got %d", n)
+	}
+	if !accepts(t, "(a|b)*abb", "babb") || accepts(t, "(a|b)*abb", "abba") {
+		t.Fatalf("unexpected acceptance for (a|b)*abb")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := map[string]int{
+		"(a":     0,
+		"a)":     1,
+		"*a":     0,
+		"a(?=b)": 1,
+		"(a)\\1": 3,
+		"[^a]":   0,
+		"[z-a]":  2,
+		"a{2}":   1,
+		"a.":     1,
+		"ab\\":   2,
+	}
+	for pattern, pos := range cases {
+		_, err := Compile(pattern)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("pattern %q: expected *ParseError, got %v", pattern, err)
+		}
+		if perr.Pos != pos {
+			t.Fatalf("pattern %q: expected error at %d, got %d (%v)", pattern, pos, perr.Pos, perr)
+		}
+	}
+}
+
+func TestToRegexRoundTrip(t *testing.T) {
+	format := func(r rune) string { return regexp.QuoteMeta(string(r)) }
+	alphabet := []rune("abc01.")
+	patterns := []string{"(a|b)*abb", "a(b|c)+a?", "[0-1]*\\.[0-1]", "(ab|ba)*c", ""}
+	for _, pattern := range patterns {
+		m := MustCompile(pattern)
+		expr, err := m.ToRegex(format)
+		if err != nil {
+			t.Fatalf("pattern %q: %v", pattern, err)
+		}
+		back, err := Compile(expr)
+		if err != nil {
+			t.Fatalf("pattern %q: generated %q does not compile: %v", pattern, expr, err)
+		}
+		words, layer := [][]rune{{}}, [][]rune{{}}
+		for n := 0; n < 5; n++ {
+			var next [][]rune
+			for _, w := range layer {
+				for _, r := range alphabet {
+					next = append(next, append(append([]rune{}, w...), r))
+				}
+			}
+			words, layer = append(words, next...), next
+		}
+		for _, w := range words {
+			want, err := m.EvalAccepting(w)
+			want = want && err == nil
+			got, err := back.EvalAccepting(w)
+			got = got && err == nil
+			if got != want {
+				t.Fatalf("pattern %q via %q disagrees on %q", pattern, expr, string(w))
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package fsm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry maps names to machines of any state and symbol types. It is safe
+// for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	machines map[string]any
+}
+
+// DefaultRegistry is the registry used by the package-level Register.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{machines: make(map[string]any)}
+}
+
+// registrable is implemented by the values a Registry accepts.
+type registrable interface {
+	isNil() bool
+}
+
+func (m *Machine[S, Sym]) isNil() bool { return m == nil }
+
+// Register adds m, which must be a *Machine or a *Lazy, under name. A Lazy is
+// only built by the first Lookup of its name. Register fails with
+// ErrMachineExists if the name is taken, and rejects an empty name, a nil m
+// and values of other types.
+func (r *Registry) Register(name string, m any) error {
+	if name == "" {
+		return fmt.Errorf("machine name must not be empty")
+	}
+	entry, ok := m.(registrable)
+	if m == nil || ok && entry.isNil() {
+		return fmt.Errorf("nil machine for %q", name)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q is %T, not a *Machine or *Lazy", ErrMachineType, name, m)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.machines[name]; ok {
+		return fmt.Errorf("%w: %q", ErrMachineExists, name)
+	}
+	r.machines[name] = m
+	return nil
+}
+
+// List returns the registered names in ascending order.
+func (r *Registry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.machines)
+}
+
+// Register adds m to DefaultRegistry under name.
+func Register(name string, m any) error {
+	return DefaultRegistry.Register(name, m)
+}
+
+// Lookup returns the machine registered in r under name. It fails with
+// ErrMachineNotFound for an unknown name and ErrMachineType if the machine is
+// not a *Machine[S, Sym] or *Lazy[S, Sym]. A Lazy is built on first use, and
+// its build error is returned.
+func Lookup[S comparable, Sym comparable](r *Registry, name string) (*Machine[S, Sym], error) {
+	r.mu.RLock()
+	v, ok := r.machines[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrMachineNotFound, name)
+	}
+	switch v := v.(type) {
+	case *Machine[S, Sym]:
+		return v, nil
+	case *Lazy[S, Sym]:
+		m, err := v.Get()
+		if err != nil {
+			return nil, fmt.Errorf("build %q: %w", name, err)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("%w: %q is %T, not %T", ErrMachineType, name, v, (*Machine[S, Sym])(nil))
+}
@@ -0,0 +1,111 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistryRegisterAndLookup(t *testing.T) {
+	r := NewRegistry()
+	m := buildMod3(t)
+	if err := r.Register("mod3", m); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := r.Register("mod3", m); !errors.Is(err, ErrMachineExists) {
+		t.Fatalf("expected ErrMachineExists, got %v", err)
+	}
+	if err := r.Register("", m); err == nil {
+		t.Fatalf("expected an error for an empty name")
+	}
+	if err := r.Register("nil", (*Machine[string, rune])(nil)); err == nil || !strings.Contains(err.Error(), `nil machine for "nil"`) {
+		t.Fatalf("expected an error for a nil machine, got %v", err)
+	}
+	if err := r.Register("untyped", nil); err == nil {
+		t.Fatalf("expected an error for a nil value")
+	}
+	if err := r.Register("number", 3); !errors.Is(err, ErrMachineType) {
+		t.Fatalf("expected ErrMachineType for a non-machine value, got %v", err)
+	}
+	got, err := Lookup[string, rune](r, "mod3")
+	if err != nil || got != m {
+		t.Fatalf("expected the registered machine, got %v, %v", got, err)
+	}
+	if _, err := Lookup[string, rune](r, "mod4"); !errors.Is(err, ErrMachineNotFound) {
+		t.Fatalf("expected ErrMachineNotFound, got %v", err)
+	}
+}
+
+func TestRegistryLookupWrongType(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("mod3", buildMod3(t)); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	_, err := Lookup[string, byte](r, "mod3")
+	if !errors.Is(err, ErrMachineType) || !strings.Contains(err.Error(), "*fsm.Machine[string,int32]") {
+		t.Fatalf("expected ErrMachineType naming the actual type, got %v", err)
+	}
+}
+
+func TestRegistryLazy(t *testing.T) {
+	r := NewRegistry()
+	builds := 0
+	lazy := NewLazy(func() (*Machine[string, rune], error) {
+		builds++
+		return buildMod3(t), nil
+	})
+	if err := r.Register("mod3", lazy); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if builds != 0 {
+		t.Fatalf("expected Register not to build the machine")
+	}
+	first, err := Lookup[string, rune](r, "mod3")
+	if err != nil {
+		t.Fatalf("unexpected lookup error: %v", err)
+	}
+	if again, _ := Lookup[string, rune](r, "mod3"); again != first || builds != 1 {
+		t.Fatalf("expected one build shared by every lookup, got %d builds", builds)
+	}
+	if _, err := Lookup[string, byte](r, "mod3"); !errors.Is(err, ErrMachineType) {
+		t.Fatalf("expected ErrMachineType, got %v", err)
+	}
+
+	failing := NewLazy(func() (*Machine[string, rune], error) { return nil, ErrEmptyLanguage })
+	if err := r.Register("broken", failing); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Lookup[string, rune](r, "broken"); !errors.Is(err, ErrEmptyLanguage) {
+		t.Fatalf("expected the build error, got %v", err)
+	}
+	if err := r.Register("nil", (*Lazy[string, rune])(nil)); err == nil {
+		t.Fatalf("expected an error for a nil Lazy")
+	}
+}
+
+func TestRegistryConcurrentUse(t *testing.T) {
+	r := NewRegistry()
+	m := buildMod3(t)
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("m%02d", i)
+			if err := r.Register(name, m); err != nil {
+				t.Errorf("unexpected register error: %v", err)
+			}
+			if _, err := Lookup[string, rune](r, name); err != nil {
+				t.Errorf("unexpected lookup error: %v", err)
+			}
+			r.List()
+		}()
+	}
+	wg.Wait()
+	if got := r.List(); len(got) != 16 || !slices.IsSorted(got) {
+		t.Fatalf("expected 16 sorted names, got %v", got)
+	}
+}
@@ -0,0 +1,131 @@
+package fsm
+
+import "fmt"
+
+// WarningKind classifies a Warning.
+type WarningKind int
+
+const (
+	// WarningOverwrittenTransition means a transition was redefined with a
+	// different target and the options allowed it.
+	WarningOverwrittenTransition WarningKind = iota
+	// WarningUnusedSymbol means a symbol has no transition.
+	WarningUnusedSymbol
+	// WarningTerminalState means a state has no outgoing transition.
+	WarningTerminalState
+	// WarningImplicitState means a state was only registered implicitly, by
+	// On, SetInitial, OnElse or From, and never declared.
+	WarningImplicitState
+)
+
+func (k WarningKind) String() string {
+	switch k {
+	case WarningOverwrittenTransition:
+		return "overwritten transition"
+	case WarningUnusedSymbol:
+		return "unused symbol"
+	case WarningTerminalState:
+		return "terminal state"
+	case WarningImplicitState:
+		return "implicit state"
+	}
+	return fmt.Sprintf("WarningKind(%d)", int(k))
+}
+
+// Warning is a finding that does not make Build fail unless
+// WithWarningsAsErrors is given.
+type Warning struct {
+	Kind    WarningKind
+	Message string
+}
+
+func (w Warning) String() string { return w.Message }
+
+// BuildReport describes what Builder.BuildWithReport found besides errors:
+// warnings and reachability and completeness diagnostics.
+type BuildReport[S comparable, Sym comparable] struct {
+	// Warnings lists overwritten transitions in definition order, then unused
+	// symbols, terminal states and implicit states in deterministic order.
+	// Findings turned into errors by an option are not repeated here.
+	Warnings []Warning
+
+	// The analyses below run for BuildWithReport only, never for Build or
+	// BuildOwned; their slices are in the package's deterministic order.
+	// Without an initial state nothing is reachable.
+
+	// Reachable lists the states reachable from the initial state.
+	Reachable []S
+	// Unreachable lists the other states.
+	Unreachable []S
+	// Dead lists the reachable states that cannot reach an accepting state.
+	Dead []S
+	// Missing lists the (state, symbol) pairs without a transition.
+	Missing []TransitionKey[S, Sym]
+	// AcceptingReachable reports whether any accepting state is reachable.
+	AcceptingReachable bool
+}
+
+// analyze fills the diagnostics of the report from the builder's definition,
+// given as the view v.
+func (r *BuildReport[S, Sym]) analyze(b *Builder[S, Sym], v *Machine[S, Sym]) {
+	reached := map[S]struct{}{}
+	var co map[S]struct{}
+	if b.initialSet {
+		reached = v.reachable(b.initialState)
+		co = v.coreachable()
+	}
+	r.Reachable, r.Unreachable, r.Dead = []S{}, []S{}, []S{}
+	for _, s := range sortedKeys(b.states) {
+		if _, ok := reached[s]; !ok {
+			r.Unreachable = append(r.Unreachable, s)
+			continue
+		}
+		r.Reachable = append(r.Reachable, s)
+		if _, ok := co[s]; !ok {
+			r.Dead = append(r.Dead, s)
+		}
+		if _, ok := b.accepting[s]; ok {
+			r.AcceptingReachable = true
+		}
+	}
+	r.Missing = []TransitionKey[S, Sym]{}
+	for _, s := range sortedKeys(b.states) {
+		for _, sym := range sortedKeys(b.symbols) {
+			key := TransitionKey[S, Sym]{From: s, Symbol: sym}
+			if _, ok := b.transitions[key]; !ok {
+				r.Missing = append(r.Missing, key)
+			}
+		}
+	}
+}
+
+// warnings collects the builder's warnings.
+func (b *Builder[S, Sym]) warnings() []Warning {
+	warnings := append([]Warning(nil), b.overwrites...)
+	if !b.options.errorOnUnusedSymbols {
+		used := make(map[Sym]struct{}, len(b.symbols))
+		for key := range b.transitions {
+			used[key.Symbol] = struct{}{}
+		}
+		for _, sym := range sortedKeys(b.symbols) {
+			if _, ok := used[sym]; !ok {
+				warnings = append(warnings, Warning{Kind: WarningUnusedSymbol, Message: fmt.Sprintf("unused symbol %v", b.symbolName(sym))})
+			}
+		}
+	}
+	if !b.options.errorOnTerminalStates {
+		sources := make(map[S]struct{})
+		for key := range b.transitions {
+			sources[key.From] = struct{}{}
+		}
+		for _, s := range sortedKeys(b.states) {
+			if _, ok := sources[s]; !ok {
+				warnings = append(warnings, Warning{Kind: WarningTerminalState, Message: fmt.Sprintf("terminal state %v", b.stateName(s))})
+			}
+		}
+	}
+	for _, s := range sortedKeys(b.implicit) {
+		warnings = append(warnings, Warning{Kind: WarningImplicitState, Message: fmt.Sprintf("implicitly registered state %v", b.stateName(s))})
+	}
+	return warnings
+}
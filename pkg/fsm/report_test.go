@@ -0,0 +1,143 @@
+package fsm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// warningsOf returns the messages of the warnings of the given kind.
+func warningsOf(r *BuildReport[string, rune], kind WarningKind) []string {
+	var out []string
+	for _, w := range r.Warnings {
+		if w.Kind == kind {
+			out = append(out, w.Message)
+		}
+	}
+	return out
+}
+
+func TestBuildWithReportCleanMachineHasNoWarnings(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.AddState("A", false).AddState("B", true).AddSymbols('x')
+	b.SetInitial("A").On("A", 'x', "B").On("B", 'x', "A")
+	m, report, err := b.BuildWithReport()
+	if err != nil || m == nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", report.Warnings)
+	}
+}
+
+func TestBuildWithReportWarningKinds(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.AddState("A", false).AddState("B", true).AddSymbols('x', 'y', 'z')
+	b.SetInitial("A")
+	b.On("A", 'x', "B").On("A", 'x', "A").On("A", 'x', "A") // one real overwrite
+	b.On("B", 'y', "Typo")
+	_, report, err := b.BuildWithReport()
+	if err != nil {
+		t.Fatalf("warnings must not fail Build: %v", err)
+	}
+	for kind, want := range map[WarningKind][]string{
+		WarningOverwrittenTransition: {"overwritten transition from A on 120: B replaced by A"},
+		WarningUnusedSymbol:          {"unused symbol 122"},
+		WarningTerminalState:         {"terminal state Typo"},
+		WarningImplicitState:         {"implicitly registered state Typo"},
+	} {
+		if got := warningsOf(report, kind); !slices.Equal(got, want) {
+			t.Errorf("%v: want %q, got %q", kind, want, got)
+		}
+	}
+
+	// Declaring the state later clears the implicit warning.
+	if _, report, _ = b.Clone().AddState("Typo", false).BuildWithReport(); len(warningsOf(report, WarningImplicitState)) != 0 {
+		t.Fatalf("expected no implicit state warning after AddState, got %v", report.Warnings)
+	}
+}
+
+func TestBuildWithReportSkipsFindingsReportedAsErrors(t *testing.T) {
+	b := NewBuilder[string, rune](WithErrorOnUnusedSymbols(), WithErrorOnTerminalStates[string]())
+	b.AddState("A", true).AddSymbols('x', 'y').SetInitial("A").On("A", 'x', "B")
+	_, report, err := b.BuildWithReport()
+	if err == nil {
+		t.Fatalf("expected build errors")
+	}
+	if len(warningsOf(report, WarningUnusedSymbol)) != 0 || len(warningsOf(report, WarningTerminalState)) != 0 {
+		t.Fatalf("expected errors not to be repeated as warnings, got %v", report.Warnings)
+	}
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	b := NewBuilder[string, rune](WithWarningsAsErrors())
+	b.AddState("A", true).AddSymbols('x', 'y').SetInitial("A").On("A", 'x', "A")
+	_, report, err := b.BuildWithReport()
+	if err == nil || !strings.Contains(err.Error(), "unused symbol 121") {
+		t.Fatalf("expected the unused symbol warning as an error, got %v", err)
+	}
+	if len(report.Warnings) != 1 {
+		t.Fatalf("expected the warning to stay in the report, got %v", report.Warnings)
+	}
+}
+
+func TestWarningKindString(t *testing.T) {
+	if got := WarningImplicitState.String(); got != "implicit state" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := WarningKind(42).String(); got != "WarningKind(42)" {
+		t.Fatalf("unexpected %q", got)
+	}
+}
+
+func TestBuildReportDiagnostics(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A").AddState("B", true).AddState("orphan", true).AddSymbols('x', 'y')
+	b.On("A", 'x', "B").On("A", 'y', "trap").SelfLoop("trap", 'x', 'y').On("orphan", 'x', "A")
+	_, report, err := b.BuildWithReport()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if !slices.Equal(report.Reachable, []string{"A", "B", "trap"}) {
+		t.Errorf("unexpected reachable %v", report.Reachable)
+	}
+	if !slices.Equal(report.Unreachable, []string{"orphan"}) {
+		t.Errorf("unexpected unreachable %v", report.Unreachable)
+	}
+	if !slices.Equal(report.Dead, []string{"trap"}) {
+		t.Errorf("unexpected dead %v", report.Dead)
+	}
+	want := []TransitionKey[string, rune]{{From: "B", Symbol: 'x'}, {From: "B", Symbol: 'y'}, {From: "orphan", Symbol: 'y'}}
+	if !slices.Equal(report.Missing, want) {
+		t.Errorf("unexpected missing %v", report.Missing)
+	}
+	if !report.AcceptingReachable {
+		t.Errorf("expected an accepting state to be reachable")
+	}
+}
+
+func TestBuildReportWithoutInitial(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.AddState("A", true).AddSymbol('x')
+	_, report, err := b.BuildWithReport()
+	if err == nil {
+		t.Fatalf("expected missing initial state error")
+	}
+	if len(report.Reachable) != 0 || !slices.Equal(report.Unreachable, []string{"A"}) || report.AcceptingReachable {
+		t.Fatalf("expected nothing to be reachable, got %+v", report)
+	}
+}
+
+func TestBuildSkipsReport(t *testing.T) {
+	b := NewBuilder[int, int](WithMutableAfterBuild())
+	b.SetInitial(0)
+	for i := range 64 {
+		b.AddSymbol(i)
+		b.On(i, i, i+1).AddState(i+1, true)
+	}
+	build := testing.AllocsPerRun(10, func() { b.Build() })
+	withReport := testing.AllocsPerRun(10, func() { b.BuildWithReport() })
+	if build >= withReport {
+		t.Fatalf("expected Build to allocate less than BuildWithReport, got %v and %v", build, withReport)
+	}
+}
@@ -1,5 +1,10 @@
 package fsm
 
+import (
+	"context"
+	"iter"
+)
+
 // Runner is a mutable execution context for a Machine.
 type Runner[S comparable, Sym comparable] struct {
 	machine *Machine[S, Sym]
@@ -14,10 +19,71 @@ func (r *Runner[S, Sym]) Step(sym Sym) error {
 	// CURSOR: Single map lookup with composite key
 	next, ok := r.machine.transitions[TransitionKey[S, Sym]{From: r.state, Symbol: sym}]
 	if !ok {
-		return &TransitionError{From: r.state, Symbol: sym}
+		return &TransitionError[S, Sym]{
+			From:        r.state,
+			Symbol:      sym,
+			Position:    -1,
+			explain:     r.machine.Explain,
+			stateNamer:  r.machine.stateNamer,
+			symbolNamer: r.machine.symbolNamer,
+		}
 	}
 	r.state = next
 	return nil
 }
 
 
+
+// StepSeq steps over the symbols of seq in order. At the first missing
+// transition it stops pulling from seq and returns the TransitionError, with
+// Position set to the symbol's index in seq; the runner stays in the state
+// reached before that symbol.
+func (r *Runner[S, Sym]) StepSeq(seq iter.Seq[Sym]) error {
+	i := 0
+	for sym := range seq {
+		if err := r.Step(sym); err != nil {
+			if te, ok := err.(*TransitionError[S, Sym]); ok {
+				te.Position = i
+			}
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+// Feed steps over the symbols received from ch until ch is closed, returning
+// nil, or ctx is done, returning an *InterruptedError wrapping ctx.Err(). A
+// missing transition stops it with the TransitionError, whose Position counts
+// the symbols received by this call; the runner stays in the state reached
+// before that symbol.
+func (r *Runner[S, Sym]) Feed(ctx context.Context, ch <-chan Sym) error {
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return r.interrupted(i, ctx.Err())
+		case sym, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := r.Step(sym); err != nil {
+				if te, ok := err.(*TransitionError[S, Sym]); ok {
+					te.Position = i
+				}
+				return err
+			}
+		}
+	}
+}
+
+// interrupted returns the InterruptedError for the runner's state before the
+// symbol at index.
+func (r *Runner[S, Sym]) interrupted(index int, err error) *InterruptedError[S] {
+	return &InterruptedError[S]{State: r.state, Index: index, Err: err, stateNamer: r.machine.stateNamer}
+}
+
+// AvailableSymbols returns the symbols on which the runner can step from its
+// current state, in deterministic order.
+func (r *Runner[S, Sym]) AvailableSymbols() []Sym {
+	return r.machine.DefinedSymbols(r.state)
+}
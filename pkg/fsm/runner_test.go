@@ -1,6 +1,12 @@
 package fsm
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+)
 
 func TestRunnerStepSequence(t *testing.T) {
 	b := NewBuilder[string, rune]()
@@ -59,3 +65,130 @@ func TestStepRowExistsButSymbolMissing(t *testing.T) {
 }
 
 
+
+func TestRunnerAvailableSymbols(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'y', "B").On("A", 'x', "B").On("B", 'z', "C")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	r := m.Start()
+	if got, want := r.AvailableSymbols(), []rune{'x', 'y'}; !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if err := r.Step('x'); err != nil {
+		t.Fatalf("unexpected step error: %v", err)
+	}
+	if got, want := r.AvailableSymbols(), []rune{'z'}; !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+// countingSeq yields input, counting how many symbols were pulled.
+func countingSeq(input string, pulled *int) iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for _, r := range input {
+			*pulled++
+			if !yield(r) {
+				return
+			}
+		}
+	}
+}
+
+func TestStepSeqStopsAtFirstError(t *testing.T) {
+	m := buildMod3(t)
+	r := m.Start()
+	pulled := 0
+	if err := r.StepSeq(countingSeq("11", &pulled)); err != nil || r.State() != "S0" {
+		t.Fatalf("expected S0, got %v, %v", r.State(), err)
+	}
+	// Consumes into the existing runner.
+	err := r.StepSeq(countingSeq("1x0000", &pulled))
+	var terr *TransitionError[string, rune]
+	if !errors.As(err, &terr) || terr.Position != 1 || terr.Symbol != 'x' {
+		t.Fatalf("expected a transition error at index 1, got %v", err)
+	}
+	if pulled != 4 {
+		t.Fatalf("expected the sequence to stop after the failing symbol, pulled %d", pulled)
+	}
+	if r.State() != "S1" {
+		t.Fatalf("expected the runner to stay in S1, got %v", r.State())
+	}
+}
+
+func TestEvalSeq(t *testing.T) {
+	m := buildMod3(t)
+	for _, in := range []string{"", "1101", "1111"} {
+		want, _ := m.Eval([]rune(in))
+		pulled := 0
+		if got, err := m.EvalSeq(countingSeq(in, &pulled)); err != nil || got != want {
+			t.Errorf("%q: want %v, got %v, %v", in, want, got, err)
+		}
+		wantOK, _ := m.EvalAccepting([]rune(in))
+		if ok, err := m.EvalAcceptingSeq(slices.Values([]rune(in))); err != nil || ok != wantOK {
+			t.Errorf("%q: want accepting=%v, got %v, %v", in, wantOK, ok, err)
+		}
+	}
+	pulled := 0
+	if _, err := m.EvalSeq(countingSeq("2111", &pulled)); err == nil || pulled != 1 {
+		t.Fatalf("expected an early error after one symbol, got %v after %d", err, pulled)
+	}
+	if ok, err := m.EvalAcceptingSeq(countingSeq("12", &pulled)); ok || err == nil {
+		t.Fatalf("expected an error for an unknown symbol, got %v, %v", ok, err)
+	}
+}
+
+func TestEvalChan(t *testing.T) {
+	m := buildMod3(t)
+	send := func(input string, closeCh bool) chan rune {
+		ch := make(chan rune)
+		go func() {
+			for _, r := range input {
+				ch <- r
+			}
+			if closeCh {
+				close(ch)
+			}
+		}()
+		return ch
+	}
+
+	// Close path.
+	if got, err := m.EvalChan(context.Background(), send("1101", true)); err != nil || got != "S1" {
+		t.Fatalf("expected S1 for 1101, got %v, %v", got, err)
+	}
+
+	// Cancellation path: the sender stalls after three symbols.
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan rune)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, r := range "111" {
+			ch <- r
+		}
+		cancel()
+	}()
+	got, err := m.EvalChan(ctx, ch)
+	<-done
+	if !errors.Is(err, context.Canceled) || got != "S1" {
+		t.Fatalf("expected cancellation in S1, got %v, %v", got, err)
+	}
+
+	// Transition-error path, with a long-lived runner fed twice.
+	r := m.Start()
+	if err := r.Feed(context.Background(), send("11", true)); err != nil || r.State() != "S0" {
+		t.Fatalf("expected S0 after 11, got %v, %v", r.State(), err)
+	}
+	err = r.Feed(context.Background(), send("12", false))
+	var terr *TransitionError[string, rune]
+	if !errors.As(err, &terr) || terr.Position != 1 || r.State() != "S1" {
+		t.Fatalf("expected a transition error at index 1 in S1, got %v in %v", err, r.State())
+	}
+	if got, err := m.EvalChan(context.Background(), send("2", false)); !errors.As(err, &terr) || got != "" {
+		t.Fatalf("expected a transition error and the zero state, got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,93 @@
+package fsm
+
+import "sync"
+
+// SafeBuilder wraps a Builder for use from multiple goroutines, e.g. when
+// transitions are discovered concurrently. Every method holds a mutex for its
+// whole duration, Build included; Do runs a compound definition atomically.
+type SafeBuilder[S comparable, Sym comparable] struct {
+	mu sync.Mutex
+	b  *Builder[S, Sym]
+}
+
+// NewSafeBuilder creates a SafeBuilder around NewBuilder(opts...).
+func NewSafeBuilder[S comparable, Sym comparable](opts ...Option) *SafeBuilder[S, Sym] {
+	return &SafeBuilder[S, Sym]{b: NewBuilder[S, Sym](opts...)}
+}
+
+// Do calls f with the underlying builder while holding the lock. f must not
+// retain the builder.
+func (sb *SafeBuilder[S, Sym]) Do(f func(b *Builder[S, Sym])) *SafeBuilder[S, Sym] {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	f(sb.b)
+	return sb
+}
+
+// AddState is Builder.AddState under the lock.
+func (sb *SafeBuilder[S, Sym]) AddState(state S, isAccepting bool) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.AddState(state, isAccepting) })
+}
+
+// AddStates is Builder.AddStates under the lock.
+func (sb *SafeBuilder[S, Sym]) AddStates(accepting bool, states ...S) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.AddStates(accepting, states...) })
+}
+
+// SetAccepting is Builder.SetAccepting under the lock.
+func (sb *SafeBuilder[S, Sym]) SetAccepting(state S, accepting bool) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.SetAccepting(state, accepting) })
+}
+
+// SetInitial is Builder.SetInitial under the lock.
+func (sb *SafeBuilder[S, Sym]) SetInitial(state S) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.SetInitial(state) })
+}
+
+// AddSymbol is Builder.AddSymbol under the lock.
+func (sb *SafeBuilder[S, Sym]) AddSymbol(sym Sym) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.AddSymbol(sym) })
+}
+
+// AddSymbols is Builder.AddSymbols under the lock.
+func (sb *SafeBuilder[S, Sym]) AddSymbols(syms ...Sym) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.AddSymbols(syms...) })
+}
+
+// On is Builder.On under the lock.
+func (sb *SafeBuilder[S, Sym]) On(from S, sym Sym, to S) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.On(from, sym, to) })
+}
+
+// OnChecked is Builder.OnChecked under the lock.
+func (sb *SafeBuilder[S, Sym]) OnChecked(from S, sym Sym, to S) error {
+	var err error
+	sb.Do(func(b *Builder[S, Sym]) { err = b.OnChecked(from, sym, to) })
+	return err
+}
+
+// OnElse is Builder.OnElse under the lock.
+func (sb *SafeBuilder[S, Sym]) OnElse(from S, to S) *SafeBuilder[S, Sym] {
+	return sb.Do(func(b *Builder[S, Sym]) { b.OnElse(from, to) })
+}
+
+// TransitionFor is Builder.TransitionFor under the lock.
+func (sb *SafeBuilder[S, Sym]) TransitionFor(from S, sym Sym) (S, bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.b.TransitionFor(from, sym)
+}
+
+// Build is Builder.Build under the lock.
+func (sb *SafeBuilder[S, Sym]) Build() (*Machine[S, Sym], error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.b.Build()
+}
+
+// BuildWithReport is Builder.BuildWithReport under the lock.
+func (sb *SafeBuilder[S, Sym]) BuildWithReport() (*Machine[S, Sym], *BuildReport[S, Sym], error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.b.BuildWithReport()
+}
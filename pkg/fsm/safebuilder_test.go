@@ -0,0 +1,57 @@
+package fsm
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestSafeBuilderConcurrentDefinition(t *testing.T) {
+	const workers, perWorker = 8, 64
+	sb := NewSafeBuilder[int, int]()
+	sb.SetInitial(0).AddState(0, true)
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each worker owns a disjoint range of symbols.
+			for i := range perWorker {
+				sym := w*perWorker + i
+				sb.AddSymbol(sym)
+				sb.On(0, sym, sym%3)
+				sb.AddState(sym%3, false)
+			}
+		}()
+	}
+	wg.Wait()
+
+	m, err := sb.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if got := len(m.EdgeList()); got != workers*perWorker {
+		t.Fatalf("expected %d transitions, got %d", workers*perWorker, got)
+	}
+	if to, ok := sb.TransitionFor(0, 100); !ok || to != 1 {
+		t.Fatalf("expected δ(0,100) = 1, got %v,%v", to, ok)
+	}
+}
+
+func TestSafeBuilderDoAndChecked(t *testing.T) {
+	sb := NewSafeBuilder[string, rune]()
+	sb.Do(func(b *Builder[string, rune]) {
+		b.SetInitial("A").AddState("A", true).SelfLoop("A", 'x')
+	})
+	var dup *DuplicateTransitionError
+	if err := sb.OnChecked("A", 'x', "B"); !errors.As(err, &dup) {
+		t.Fatalf("expected DuplicateTransitionError, got %v", err)
+	}
+	if _, err := sb.Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if err := sb.OnChecked("A", 'y', "A"); !errors.Is(err, ErrBuilderFrozen) {
+		t.Fatalf("expected the wrapped builder to freeze, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package fsm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// State marks a field of a BuildFromSpec struct as a state named after the
+// field.
+type State struct{}
+
+// SpecError reports a malformed BuildFromSpec struct, naming the field and
+// the tag fragment at fault.
+type SpecError struct {
+	Field    string
+	Fragment string
+	Msg      string
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("spec field %s: %s in %q", e.Field, e.Msg, e.Fragment)
+}
+
+// BuildFromSpec builds a machine declared by the struct spec, or a pointer to
+// it, through a Builder configured with opts:
+//
+//	type Mod3 struct {
+//		S0          fsm.State `fsm:"initial,accepting"`
+//		S1, S2      fsm.State
+//		Transitions struct{}  `fsm:"0:S0>S0,1:S0>S1,0:S1>S2,1:S1>S0,0:S2>S1,1:S2>S2"`
+//	}
+//
+// Each field of type State is a state; its optional tag lists "initial"
+// and/or "accepting". Any other field with an fsm tag lists transitions as
+// comma-separated sym:from>to fragments, where sym is a single rune other than
+// ',' and from and to name State fields. Fields tagged fsm:"-" are skipped.
+// Malformed tags are reported as *SpecError; the rest is validated by Build.
+func BuildFromSpec(spec any, opts ...Option) (*Machine[string, rune], error) {
+	v := reflect.ValueOf(spec)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("spec must be a struct or a pointer to one, got %T", spec)
+	}
+	t := v.Type()
+	b := NewBuilder[string, rune](opts...)
+	stateType := reflect.TypeFor[State]()
+
+	// States first, so transitions can be checked against them.
+	initial := ""
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("fsm")
+		if tag == "-" || f.Type != stateType {
+			continue
+		}
+		b.AddState(f.Name, false)
+		if !tagged || tag == "" {
+			continue
+		}
+		for _, opt := range strings.Split(tag, ",") {
+			switch opt {
+			case "initial":
+				if initial != "" {
+					return nil, &SpecError{Field: f.Name, Fragment: opt, Msg: "second initial state after " + initial}
+				}
+				initial = f.Name
+				b.SetInitial(f.Name)
+			case "accepting":
+				b.AddState(f.Name, true)
+			default:
+				return nil, &SpecError{Field: f.Name, Fragment: opt, Msg: "unknown state option"}
+			}
+		}
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("fsm")
+		if !tagged || tag == "-" || f.Type == stateType {
+			continue
+		}
+		for _, frag := range strings.Split(tag, ",") {
+			if err := specTransition(b, f.Name, frag); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.BuildOwned()
+}
+
+// specTransition adds the transition described by the sym:from>to fragment of
+// field's tag.
+func specTransition(b *Builder[string, rune], field, frag string) error {
+	sym, size := utf8.DecodeRuneInString(frag)
+	if size == 0 || sym == utf8.RuneError && size == 1 {
+		return &SpecError{Field: field, Fragment: frag, Msg: "missing symbol"}
+	}
+	rest, ok := strings.CutPrefix(frag[size:], ":")
+	if !ok {
+		return &SpecError{Field: field, Fragment: frag, Msg: "missing ':' after the symbol"}
+	}
+	from, to, ok := strings.Cut(rest, ">")
+	if !ok {
+		return &SpecError{Field: field, Fragment: frag, Msg: "missing '>' between states"}
+	}
+	for _, s := range []string{from, to} {
+		if _, ok := b.states[s]; !ok {
+			return &SpecError{Field: field, Fragment: frag, Msg: fmt.Sprintf("unknown state %q", s)}
+		}
+	}
+	b.AddSymbol(sym).On(from, sym, to)
+	return nil
+}
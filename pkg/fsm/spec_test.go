@@ -0,0 +1,78 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type toggleSpec struct {
+	Off, On State
+	Lit     State    `fsm:"accepting"`
+	Start   State    `fsm:"initial"`
+	Ignored State    `fsm:"-"`
+	Edges   struct{} `fsm:"t:Start>Off,t:Off>On,t:On>Off,::On>Lit"`
+	Notes   string
+}
+
+func TestBuildFromSpec(t *testing.T) {
+	for _, spec := range []any{toggleSpec{}, &toggleSpec{}} {
+		m, err := BuildFromSpec(spec, WithStrictRegistration())
+		if err != nil {
+			t.Fatalf("unexpected build error: %v", err)
+		}
+		if m.InitialState() != "Start" || !m.Accepting("Lit") || m.Accepting("On") {
+			t.Fatalf("expected Start initial and only Lit accepting")
+		}
+		if got, err := m.Eval([]rune("tt:")); err != nil || got != "Lit" {
+			t.Fatalf("expected tt: to reach Lit, got %v, %v", got, err)
+		}
+		if len(m.States()) != 4 {
+			t.Fatalf("expected the skipped field to be left out, got %v", m.States())
+		}
+	}
+}
+
+func TestBuildFromSpecErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		spec           any
+		field, message string
+	}{
+		"missing colon": {struct {
+			A State    `fsm:"initial"`
+			T struct{} `fsm:"x:A>A,xA>A"`
+		}{}, "T", `missing ':' after the symbol in "xA>A"`},
+		"missing arrow": {struct {
+			A State    `fsm:"initial"`
+			T struct{} `fsm:"x:A-A"`
+		}{}, "T", `missing '>' between states in "x:A-A"`},
+		"empty fragment": {struct {
+			A State    `fsm:"initial"`
+			T struct{} `fsm:"x:A>A,"`
+		}{}, "T", `missing symbol in ""`},
+		"unknown state": {struct {
+			A State    `fsm:"initial"`
+			T struct{} `fsm:"x:A>B"`
+		}{}, "T", `unknown state "B" in "x:A>B"`},
+		"unknown option": {struct {
+			A State `fsm:"initial,final"`
+		}{}, "A", `unknown state option in "final"`},
+		"second initial": {struct {
+			A State `fsm:"initial"`
+			B State `fsm:"initial"`
+		}{}, "B", `second initial state after A in "initial"`},
+	} {
+		_, err := BuildFromSpec(tc.spec)
+		var serr *SpecError
+		if !errors.As(err, &serr) || serr.Field != tc.field || !strings.Contains(err.Error(), tc.message) {
+			t.Errorf("%s: expected SpecError on %s with %q, got %v", name, tc.field, tc.message, err)
+		}
+	}
+	if _, err := BuildFromSpec(42); err == nil || !strings.Contains(err.Error(), "got int") {
+		t.Fatalf("expected an error for a non-struct spec, got %v", err)
+	}
+	// Definitions that parse are still validated by Build.
+	if _, err := BuildFromSpec(struct{ A State }{}); err == nil || !strings.Contains(err.Error(), "initial state must be set") {
+		t.Fatalf("expected a build error, got %v", err)
+	}
+}
@@ -0,0 +1,309 @@
+package fsm
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ToRegex returns a regular expression describing the language accepted by the
+// machine, computed by state elimination over a generalized NFA. Each symbol is
+// rendered with format, which is responsible for escaping metacharacters (for
+// rune machines regexp.QuoteMeta is a good choice). The expression is implicitly
+// anchored: it matches whole inputs. It uses only literals, |, *, ? and
+// character classes, so it can be compiled by both regexp and regexfsm.
+//
+// The result is not minimal, but redundant epsilon terms are dropped and
+// alternations of single-rune symbols are collapsed into classes. An empty
+// language returns ErrEmptyLanguage since it has no conventional notation.
+func (m *Machine[S, Sym]) ToRegex(format func(Sym) string) (string, error) {
+	trimmed, err := m.Trim()
+	if err != nil {
+		return "", err
+	}
+	rb := &regexBuilder[Sym]{format: format}
+
+	// GNFA nodes: 0 is the new start, 1 the new final, trimmed states follow.
+	states := sortedKeys(trimmed.reachable(trimmed.initialState))
+	index := make(map[S]int, len(states))
+	for i, s := range states {
+		index[s] = i + 2
+	}
+	n := len(states) + 2
+	edges := make([][]*regexNode[Sym], n)
+	for i := range edges {
+		edges[i] = make([]*regexNode[Sym], n)
+	}
+	edges[0][index[trimmed.initialState]] = rb.epsilon()
+	for _, s := range states {
+		if trimmed.Accepting(s) {
+			edges[index[s]][1] = rb.epsilon()
+		}
+		for _, sym := range trimmed.sortedSymbols() {
+			if to, ok := trimmed.GetTransition(s, sym); ok {
+				p, q := index[s], index[to]
+				edges[p][q] = rb.union(edges[p][q], rb.symbol(sym))
+			}
+		}
+	}
+
+	// Eliminate interior nodes, cheapest (fewest in*out edges) first.
+	alive := make([]bool, n)
+	for i := range alive {
+		alive[i] = true
+	}
+	for remaining := len(states); remaining > 0; remaining-- {
+		best, bestCost := -1, 0
+		for k := 2; k < n; k++ {
+			if !alive[k] {
+				continue
+			}
+			in, out := 0, 0
+			for j := 0; j < n; j++ {
+				if alive[j] && j != k && edges[j][k] != nil {
+					in++
+				}
+				if alive[j] && j != k && edges[k][j] != nil {
+					out++
+				}
+			}
+			if cost := in * out; best < 0 || cost < bestCost {
+				best, bestCost = k, cost
+			}
+		}
+		k := best
+		loop := rb.star(edges[k][k])
+		for p := 0; p < n; p++ {
+			if !alive[p] || p == k || edges[p][k] == nil {
+				continue
+			}
+			for q := 0; q < n; q++ {
+				if !alive[q] || q == k || edges[k][q] == nil {
+					continue
+				}
+				edges[p][q] = rb.union(edges[p][q], rb.concat(rb.concat(edges[p][k], loop), edges[k][q]))
+			}
+		}
+		alive[k] = false
+	}
+	expr, _ := rb.render(edges[0][1])
+	return expr, nil
+}
+
+type regexKind int
+
+const (
+	regexEpsilon regexKind = iota
+	regexSymbol
+	regexUnion
+	regexConcat
+	regexStar
+)
+
+// regexNode is a node of a regular expression tree. A nil node denotes the
+// empty language.
+type regexNode[Sym comparable] struct {
+	kind regexKind
+	sym  Sym
+	kids []*regexNode[Sym]
+}
+
+// Rendering precedence levels, loosest first.
+const (
+	precUnion = iota
+	precConcat
+	precRepeat
+	precAtom
+)
+
+// regexBuilder constructs simplified expression trees and renders them.
+type regexBuilder[Sym comparable] struct {
+	format func(Sym) string
+}
+
+func (rb *regexBuilder[Sym]) epsilon() *regexNode[Sym] {
+	return &regexNode[Sym]{kind: regexEpsilon}
+}
+
+func (rb *regexBuilder[Sym]) symbol(sym Sym) *regexNode[Sym] {
+	return &regexNode[Sym]{kind: regexSymbol, sym: sym}
+}
+
+func (rb *regexBuilder[Sym]) union(a, b *regexNode[Sym]) *regexNode[Sym] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	var kids []*regexNode[Sym]
+	seen := make(map[string]struct{})
+	for _, n := range []*regexNode[Sym]{a, b} {
+		members := []*regexNode[Sym]{n}
+		if n.kind == regexUnion {
+			members = n.kids
+		}
+		for _, k := range members {
+			key, _ := rb.render(k)
+			if k.kind == regexEpsilon {
+				key = "\x00eps"
+			}
+			if _, dup := seen[key]; !dup {
+				seen[key] = struct{}{}
+				kids = append(kids, k)
+			}
+		}
+	}
+	if len(kids) == 1 {
+		return kids[0]
+	}
+	return &regexNode[Sym]{kind: regexUnion, kids: kids}
+}
+
+func (rb *regexBuilder[Sym]) concat(a, b *regexNode[Sym]) *regexNode[Sym] {
+	if a == nil || b == nil {
+		return nil
+	}
+	if a.kind == regexEpsilon {
+		return b
+	}
+	if b.kind == regexEpsilon {
+		return a
+	}
+	var kids []*regexNode[Sym]
+	for _, n := range []*regexNode[Sym]{a, b} {
+		if n.kind == regexConcat {
+			kids = append(kids, n.kids...)
+		} else {
+			kids = append(kids, n)
+		}
+	}
+	return &regexNode[Sym]{kind: regexConcat, kids: kids}
+}
+
+func (rb *regexBuilder[Sym]) star(a *regexNode[Sym]) *regexNode[Sym] {
+	if a == nil || a.kind == regexEpsilon {
+		return rb.epsilon()
+	}
+	if a.kind == regexStar {
+		return a
+	}
+	if a.kind == regexUnion {
+		// (x|ε)* == x*
+		var kids []*regexNode[Sym]
+		for _, k := range a.kids {
+			if k.kind != regexEpsilon {
+				kids = append(kids, k)
+			}
+		}
+		if len(kids) == 1 {
+			return rb.star(kids[0])
+		}
+		a = &regexNode[Sym]{kind: regexUnion, kids: kids}
+	}
+	return &regexNode[Sym]{kind: regexStar, kids: []*regexNode[Sym]{a}}
+}
+
+// render returns the textual form of n together with its precedence level.
+func (rb *regexBuilder[Sym]) render(n *regexNode[Sym]) (string, int) {
+	switch n.kind {
+	case regexEpsilon:
+		return "", precAtom
+	case regexSymbol:
+		s := rb.format(n.sym)
+		if utf8.RuneCountInString(s) == 1 {
+			return s, precAtom
+		}
+		return s, precConcat
+	case regexStar:
+		s, prec := rb.render(n.kids[0])
+		return rb.wrap(s, prec, precAtom) + "*", precRepeat
+	case regexConcat:
+		var sb strings.Builder
+		for _, k := range n.kids {
+			s, prec := rb.render(k)
+			sb.WriteString(rb.wrap(s, prec, precConcat))
+		}
+		return sb.String(), precConcat
+	}
+
+	// Union: collapse single-rune symbols into a class, mark epsilon as optional.
+	optional := false
+	var runes []rune
+	var parts []string
+	prec := precAtom
+	for _, k := range n.kids {
+		switch k.kind {
+		case regexEpsilon:
+			optional = true
+			continue
+		case regexSymbol:
+			if s := rb.format(k.sym); utf8.RuneCountInString(s) == 1 {
+				r, _ := utf8.DecodeRuneInString(s)
+				runes = append(runes, r)
+				continue
+			}
+		}
+		s, p := rb.render(k)
+		parts = append(parts, s)
+		prec = p
+	}
+	switch len(runes) {
+	case 0:
+	case 1:
+		parts = append([]string{string(runes[0])}, parts...)
+	default:
+		parts = append([]string{renderClass(runes)}, parts...)
+	}
+	body := parts[0]
+	if len(parts) > 1 {
+		body, prec = strings.Join(parts, "|"), precUnion
+	} else if len(runes) > 0 {
+		prec = precAtom
+	}
+	if optional {
+		return rb.wrap(body, prec, precAtom) + "?", precRepeat
+	}
+	return body, prec
+}
+
+// wrap parenthesizes s when its precedence is looser than required.
+func (rb *regexBuilder[Sym]) wrap(s string, prec, required int) string {
+	if prec < required {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// renderClass renders a bracketed class, folding runs of three or more
+// consecutive runes into ranges.
+func renderClass(runes []rune) string {
+	runes = slices.Clone(runes)
+	slices.Sort(runes)
+	runes = slices.Compact(runes)
+	escape := func(r rune) string {
+		switch r {
+		case '\\', ']', '[', '-', '^':
+			return `\` + string(r)
+		}
+		return string(r)
+	}
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			sb.WriteString(escape(runes[i]) + "-" + escape(runes[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				sb.WriteString(escape(runes[k]))
+			}
+		}
+		i = j + 1
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
@@ -0,0 +1,94 @@
+package fsm
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func quoteRune(r rune) string { return regexp.QuoteMeta(string(r)) }
+
+// assertRegexEquivalent checks that expr, anchored, matches exactly the inputs m accepts.
+func assertRegexEquivalent(t *testing.T, m *Machine[string, rune], expr string, alphabet []rune, maxLen int) {
+	t.Helper()
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		t.Fatalf("generated expression %q does not compile: %v", expr, err)
+	}
+	for _, w := range allStrings(alphabet, maxLen) {
+		if re.MatchString(string(w)) != acceptsOrRejects(m, w) {
+			t.Fatalf("expression %q disagrees with machine on %q", expr, string(w))
+		}
+	}
+}
+
+func TestToRegexMod3(t *testing.T) {
+	m := buildMod3(t)
+	expr, err := m.ToRegex(quoteRune)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRegexEquivalent(t, m, expr, []rune{'0', '1'}, 10)
+}
+
+func TestToRegexCollapsesClasses(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	for _, r := range "abcx" {
+		b.On("A", r, "B")
+	}
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	expr, err := m.ToRegex(quoteRune)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expr != "[a-cx]" {
+		t.Fatalf("expected [a-cx], got %q", expr)
+	}
+}
+
+func TestToRegexOptionalAndMetacharacters(t *testing.T) {
+	// Accepts "", "." and ".*" followed by any number of "-".
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("A", true).AddState("B", true).AddState("C", true)
+	b.On("A", '.', "B").On("B", '*', "C").On("C", '-', "C").On("C", ']', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	expr, err := m.ToRegex(quoteRune)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRegexEquivalent(t, m, expr, []rune{'.', '*', '-', ']'}, 6)
+}
+
+func TestToRegexEmptyLanguage(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if _, err := m.ToRegex(quoteRune); !errors.Is(err, ErrEmptyLanguage) {
+		t.Fatalf("expected ErrEmptyLanguage, got %v", err)
+	}
+
+	b2 := NewBuilder[string, rune]()
+	b2.SetInitial("A")
+	b2.AddState("A", true)
+	b2.On("A", 'x', "Trap")
+	m2, err := b2.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if expr, err := m2.ToRegex(quoteRune); err != nil || expr != "" {
+		t.Fatalf("expected empty expression for {ε}, got %q, err: %v", expr, err)
+	}
+}
@@ -0,0 +1,105 @@
+package fsm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TraceStep is one transition taken by an evaluation: the symbol at Index of
+// the input led from From to To.
+type TraceStep[S comparable, Sym comparable] struct {
+	Index  int
+	Symbol Sym
+	From   S
+	To     S
+}
+
+// Trace is the path followed by EvalTrace, from the initial state through
+// every transition taken, up to the failing symbol if any.
+type Trace[S comparable, Sym comparable] struct {
+	Initial S
+	Steps   []TraceStep[S, Sym]
+	// Dropped counts the earliest steps left out under WithTraceCapacity.
+	Dropped int
+
+	// Namers of the machine, from WithStateNamer and WithSymbolNamer.
+	stateNamer, symbolNamer func(any) string
+}
+
+// String renders the trace as a step log, one transition per line.
+func (t Trace[S, Sym]) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "start %v", nameWith(t.stateNamer, t.Initial))
+	if t.Dropped > 0 {
+		fmt.Fprintf(&sb, "\n... %d steps dropped", t.Dropped)
+	}
+	for _, s := range t.Steps {
+		fmt.Fprintf(&sb, "\n%d: %v --%v--> %v", s.Index, nameWith(t.stateNamer, s.From), nameWith(t.symbolNamer, s.Symbol), nameWith(t.stateNamer, s.To))
+	}
+	return sb.String()
+}
+
+// TraceOption configures EvalTrace.
+type TraceOption func(*traceOptions)
+
+type traceOptions struct {
+	capacity int
+}
+
+// WithTraceCapacity makes EvalTrace keep only the n most recent steps, so
+// memory stays bounded on huge inputs while a failing trace still shows how
+// the failure was reached. n <= 0 means no limit, the default.
+func WithTraceCapacity(n int) TraceOption {
+	return func(o *traceOptions) { o.capacity = n }
+}
+
+// EvalTraceFunc is Eval calling visit with every transition taken, in order.
+// It is the allocation-free alternative to EvalTrace.
+func (m *Machine[S, Sym]) EvalTraceFunc(input []Sym, visit func(TraceStep[S, Sym])) (S, error) {
+	r := m.Start()
+	for i, sym := range input {
+		from := r.State()
+		if err := r.Step(sym); err != nil {
+			if te, ok := err.(*TransitionError[S, Sym]); ok {
+				te.Position = i
+			}
+			var zero S
+			return zero, err
+		}
+		visit(TraceStep[S, Sym]{Index: i, Symbol: sym, From: from, To: r.State()})
+	}
+	return r.State(), nil
+}
+
+// EvalTrace evaluates input like Eval and returns the trace of the
+// transitions taken. On a missing transition the trace is returned with the
+// TransitionError and ends before the failing symbol.
+func (m *Machine[S, Sym]) EvalTrace(input []Sym, opts ...TraceOption) (Trace[S, Sym], error) {
+	var o traceOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t := Trace[S, Sym]{Initial: m.initialState, stateNamer: m.stateNamer, symbolNamer: m.symbolNamer}
+	limit := len(input)
+	if o.capacity > 0 && o.capacity < limit {
+		limit = o.capacity
+	}
+	t.Steps = make([]TraceStep[S, Sym], 0, limit)
+	// Past the capacity, steps overwrite the oldest one in ring order from
+	// head; the ring is unrolled at the end.
+	head := 0
+	_, err := m.EvalTraceFunc(input, func(s TraceStep[S, Sym]) {
+		if len(t.Steps) < limit {
+			t.Steps = append(t.Steps, s)
+			return
+		}
+		t.Steps[head] = s
+		head = (head + 1) % limit
+		t.Dropped++
+	})
+	if head > 0 {
+		t.Steps = slices.Concat(t.Steps[head:], t.Steps[:head])
+	}
+	return t, err
+}
@@ -0,0 +1,69 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEvalTrace(t *testing.T) {
+	m := buildMod3(t)
+	trace, err := m.EvalTrace([]rune("110"))
+	if err != nil {
+		t.Fatalf("unexpected eval error: %v", err)
+	}
+	want := []TraceStep[string, rune]{
+		{Index: 0, Symbol: '1', From: "S0", To: "S1"},
+		{Index: 1, Symbol: '1', From: "S1", To: "S0"},
+		{Index: 2, Symbol: '0', From: "S0", To: "S0"},
+	}
+	if trace.Initial != "S0" || fmt.Sprint(trace.Steps) != fmt.Sprint(want) {
+		t.Fatalf("unexpected trace %+v", trace)
+	}
+	wantLog := "start S0\n0: S0 --49--> S1\n1: S1 --49--> S0\n2: S0 --48--> S0"
+	if got := trace.String(); got != wantLog {
+		t.Fatalf("expected log\n%s\ngot\n%s", wantLog, got)
+	}
+}
+
+func TestEvalTraceStopsAtFailure(t *testing.T) {
+	m := buildMod3(t, WithSymbolNamer(func(v any) string { return fmt.Sprintf("%q", v) }))
+	trace, err := m.EvalTrace([]rune("10x1"))
+	var terr *TransitionError[string, rune]
+	if !errors.As(err, &terr) || terr.Position != 2 {
+		t.Fatalf("expected a transition error at position 2, got %v", err)
+	}
+	if len(trace.Steps) != 2 || trace.Steps[1].To != "S2" {
+		t.Fatalf("expected the trace to end in S2 before the failure, got %+v", trace.Steps)
+	}
+	if !strings.HasSuffix(trace.String(), "1: S1 --'0'--> S2") {
+		t.Fatalf("expected named symbols in the log, got %q", trace)
+	}
+}
+
+func TestEvalTraceCapacityKeepsMostRecent(t *testing.T) {
+	m := buildMod3(t)
+	input := []rune(strings.Repeat("1", 10) + "x")
+	trace, err := m.EvalTrace(input, WithTraceCapacity(3))
+	if err == nil {
+		t.Fatalf("expected a transition error")
+	}
+	if trace.Dropped != 7 || len(trace.Steps) != 3 || cap(trace.Steps) > 3 {
+		t.Fatalf("expected 3 kept and 7 dropped steps, got %d kept (cap %d), %d dropped", len(trace.Steps), cap(trace.Steps), trace.Dropped)
+	}
+	for i, s := range trace.Steps {
+		if s.Index != 7+i {
+			t.Fatalf("expected steps 7..9 in order, got %+v", trace.Steps)
+		}
+	}
+	if !strings.Contains(trace.String(), "... 7 steps dropped\n7: ") {
+		t.Fatalf("expected the log to note dropped steps, got %q", trace)
+	}
+
+	var visited []int
+	final, err := m.EvalTraceFunc([]rune("111"), func(s TraceStep[string, rune]) { visited = append(visited, s.Index) })
+	if err != nil || final != "S1" || len(visited) != 3 {
+		t.Fatalf("expected three visits ending in S1, got %v, %v, %v", visited, final, err)
+	}
+}
@@ -0,0 +1,231 @@
+package fsm
+
+import "fmt"
+
+// Transformations producing new machines from existing ones. The source
+// machine is never modified.
+
+// Trim returns a machine restricted to the useful states: those reachable from
+// the initial state from which some accepting state is reachable. Transitions
+// touching removed states are dropped; the alphabet is kept. If the initial
+// state itself is not useful, ErrEmptyLanguage is returned.
+func (m *Machine[S, Sym]) Trim() (*Machine[S, Sym], error) {
+	useful := m.useful()
+	if _, ok := useful[m.initialState]; !ok {
+		return nil, ErrEmptyLanguage
+	}
+	acc := make(map[S]struct{})
+	for s := range m.accepting {
+		if _, ok := useful[s]; ok {
+			acc[s] = struct{}{}
+		}
+	}
+	syms := make(map[Sym]struct{}, len(m.symbols))
+	for sym := range m.symbols {
+		syms[sym] = struct{}{}
+	}
+	trans := make(map[TransitionKey[S, Sym]]S)
+	for key, to := range m.transitions {
+		_, fromOK := useful[key.From]
+		_, toOK := useful[to]
+		if fromOK && toOK {
+			trans[key] = to
+		}
+	}
+	return newMachine(m.initialState, useful, acc, syms, trans).withNamers(m), nil
+}
+
+// WithAccepting returns a machine with the same states and transitions as m
+// but with exactly the given accepting states. The transition data is shared,
+// which is safe since machines are immutable; m itself is unaffected. Unknown
+// states yield an *UnknownStateError.
+func (m *Machine[S, Sym]) WithAccepting(states ...S) (*Machine[S, Sym], error) {
+	acc := make(map[S]struct{}, len(states))
+	for _, s := range states {
+		if !m.hasState(s) {
+			return nil, &UnknownStateError{State: s}
+		}
+		acc[s] = struct{}{}
+	}
+	return newMachineOrdered(m.initialState, m.stateList, m.states, acc, m.symbols, m.transitions).withNamers(m), nil
+}
+
+// WithAcceptingFunc is like WithAccepting but accepts the states for which pred
+// returns true.
+func (m *Machine[S, Sym]) WithAcceptingFunc(pred func(S) bool) *Machine[S, Sym] {
+	acc := make(map[S]struct{})
+	for _, s := range m.stateList {
+		if pred(s) {
+			acc[s] = struct{}{}
+		}
+	}
+	return newMachineOrdered(m.initialState, m.stateList, m.states, acc, m.symbols, m.transitions).withNamers(m)
+}
+
+// WithInitial returns a machine with the same states, transitions and accepting
+// set as m but starting in state. The data is shared; m itself is unaffected.
+// An unknown state yields an *UnknownStateError.
+func (m *Machine[S, Sym]) WithInitial(state S) (*Machine[S, Sym], error) {
+	if !m.hasState(state) {
+		return nil, &UnknownStateError{State: state}
+	}
+	list := make([]S, 0, len(m.stateList))
+	list = append(list, state)
+	for _, s := range m.stateList {
+		if s != state {
+			list = append(list, s)
+		}
+	}
+	return newMachineOrdered(state, list, m.states, m.accepting, m.symbols, m.transitions).withNamers(m), nil
+}
+
+// Complete returns a machine with a total transition function. Every missing
+// (state, symbol) pair, and every pair of the sink itself, is routed to sink,
+// which is non-accepting. The sink may name an existing state only if that
+// state is already a proper sink: non-accepting with self-loops only.
+func (m *Machine[S, Sym]) Complete(sink S) (*Machine[S, Sym], error) {
+	states := m.States()
+	for _, s := range states {
+		if s != sink {
+			continue
+		}
+		if m.Accepting(sink) {
+			return nil, fmt.Errorf("sink state %v must not be accepting", sink)
+		}
+		for sym := range m.symbols {
+			if to, ok := m.GetTransition(sink, sym); ok && to != sink {
+				return nil, fmt.Errorf("sink state %v collides with existing state that leaves on %v", sink, sym)
+			}
+		}
+	}
+
+	acc := make(map[S]struct{}, len(m.accepting))
+	for s := range m.accepting {
+		acc[s] = struct{}{}
+	}
+	syms := make(map[Sym]struct{}, len(m.symbols))
+	for sym := range m.symbols {
+		syms[sym] = struct{}{}
+	}
+	trans := make(map[TransitionKey[S, Sym]]S, (len(states)+1)*len(syms))
+	for key, to := range m.transitions {
+		trans[key] = to
+	}
+	stateSet := make(map[S]struct{}, len(states)+1)
+	for _, s := range append(states, sink) {
+		stateSet[s] = struct{}{}
+		for sym := range syms {
+			key := TransitionKey[S, Sym]{From: s, Symbol: sym}
+			if _, ok := trans[key]; !ok {
+				trans[key] = sink
+			}
+		}
+	}
+	return newMachine(m.initialState, stateSet, acc, syms, trans).withNamers(m), nil
+}
+
+// Normalize renames states to 0..n-1 in breadth-first order from the initial
+// state, exploring symbols in the given order. Symbols not listed in order are
+// explored afterwards in the package's deterministic order; a nil order uses
+// that default entirely. States unreachable from the initial state are numbered
+// last, also deterministically. The rename map from old to new states is
+// returned alongside the new machine.
+func (m *Machine[S, Sym]) Normalize(order []Sym) (*Machine[int, Sym], map[S]int) {
+	syms := make([]Sym, 0, len(m.symbols))
+	listed := make(map[Sym]struct{}, len(order))
+	for _, sym := range order {
+		if _, ok := m.symbols[sym]; ok {
+			if _, dup := listed[sym]; !dup {
+				listed[sym] = struct{}{}
+				syms = append(syms, sym)
+			}
+		}
+	}
+	for _, sym := range m.sortedSymbols() {
+		if _, ok := listed[sym]; !ok {
+			syms = append(syms, sym)
+		}
+	}
+
+	rename := map[S]int{m.initialState: 0}
+	queue := []S{m.initialState}
+	for i := 0; i < len(queue); i++ {
+		for _, sym := range syms {
+			to, ok := m.GetTransition(queue[i], sym)
+			if !ok {
+				continue
+			}
+			if _, ok := rename[to]; !ok {
+				rename[to] = len(rename)
+				queue = append(queue, to)
+			}
+		}
+	}
+	rest := make(map[S]struct{})
+	for _, s := range m.States() {
+		if _, ok := rename[s]; !ok {
+			rest[s] = struct{}{}
+		}
+	}
+	for _, s := range sortedKeys(rest) {
+		rename[s] = len(rename)
+	}
+
+	acc := make(map[int]struct{}, len(m.accepting))
+	for s := range m.accepting {
+		acc[rename[s]] = struct{}{}
+	}
+	symSet := make(map[Sym]struct{}, len(m.symbols))
+	for sym := range m.symbols {
+		symSet[sym] = struct{}{}
+	}
+	trans := make(map[TransitionKey[int, Sym]]int, len(m.transitions))
+	for key, to := range m.transitions {
+		trans[TransitionKey[int, Sym]{From: rename[key.From], Symbol: key.Symbol}] = rename[to]
+	}
+	states := make(map[int]struct{}, len(rename))
+	for _, id := range rename {
+		states[id] = struct{}{}
+	}
+	// States are renamed, so only the symbol namer still applies.
+	out := newMachine(0, states, acc, symSet, trans)
+	out.symbolNamer = m.symbolNamer
+	return out, rename
+}
+
+// MapSymbols translates the alphabet of m through f, producing an equivalent
+// machine over the new symbol type. It fails if f maps two distinct symbols to
+// the same new symbol, as that would make the machine nondeterministic.
+func MapSymbols[S comparable, A comparable, B comparable](m *Machine[S, A], f func(A) B) (*Machine[S, B], error) {
+	mapped := make(map[A]B, len(m.symbols))
+	origin := make(map[B]A, len(m.symbols))
+	for _, a := range m.sortedSymbols() {
+		b := f(a)
+		if prev, ok := origin[b]; ok {
+			return nil, fmt.Errorf("symbols %v and %v both map to %v", prev, a, b)
+		}
+		origin[b] = a
+		mapped[a] = b
+	}
+
+	acc := make(map[S]struct{}, len(m.accepting))
+	for s := range m.accepting {
+		acc[s] = struct{}{}
+	}
+	syms := make(map[B]struct{}, len(mapped))
+	for _, b := range mapped {
+		syms[b] = struct{}{}
+	}
+	trans := make(map[TransitionKey[S, B]]S, len(m.transitions))
+	for key, to := range m.transitions {
+		trans[TransitionKey[S, B]{From: key.From, Symbol: mapped[key.Symbol]}] = to
+	}
+	states := make(map[S]struct{}, len(m.states))
+	for s := range m.states {
+		states[s] = struct{}{}
+	}
+	// Symbols are translated, so only the state namer still applies.
+	out := newMachine(m.initialState, states, acc, syms, trans)
+	out.stateNamer = m.stateNamer
+	return out, nil
+}
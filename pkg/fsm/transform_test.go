@@ -0,0 +1,342 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// allStrings returns every string over alphabet of length at most maxLen.
+func allStrings(alphabet []rune, maxLen int) [][]rune {
+	out := [][]rune{{}}
+	layer := [][]rune{{}}
+	for n := 1; n <= maxLen; n++ {
+		var next [][]rune
+		for _, w := range layer {
+			for _, r := range alphabet {
+				next = append(next, append(append([]rune{}, w...), r))
+			}
+		}
+		out = append(out, next...)
+		layer = next
+	}
+	return out
+}
+
+// acceptsOrRejects treats transition errors as rejection.
+func acceptsOrRejects[S comparable](m *Machine[S, rune], w []rune) bool {
+	ok, err := m.EvalAccepting(w)
+	return err == nil && ok
+}
+
+func assertSameLanguage[S1, S2 comparable](t *testing.T, a *Machine[S1, rune], b *Machine[S2, rune], alphabet []rune, maxLen int) {
+	t.Helper()
+	for _, w := range allStrings(alphabet, maxLen) {
+		if acceptsOrRejects(a, w) != acceptsOrRejects(b, w) {
+			t.Fatalf("machines disagree on %q", string(w))
+		}
+	}
+}
+
+func TestTrimDropsUselessStates(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'a', "B").On("B", 'b', "A")
+	b.On("A", 'x', "Trap").On("Trap", 'x', "Trap")
+	b.On("Orphan", 'a', "B")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	tm, err := m.Trim()
+	if err != nil {
+		t.Fatalf("unexpected trim error: %v", err)
+	}
+	if n := len(tm.States()); n != 2 {
+		t.Fatalf("expected 2 states after trim, got %v", tm.States())
+	}
+	if tm.HasTransition("A", 'x') {
+		t.Fatalf("expected transition into Trap to be dropped")
+	}
+	if !m.HasTransition("A", 'x') {
+		t.Fatalf("original machine must be unaffected")
+	}
+	assertSameLanguage(t, m, tm, []rune{'a', 'b', 'x'}, 6)
+}
+
+func TestTrimEmptyLanguage(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'x', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if _, err := m.Trim(); !errors.Is(err, ErrEmptyLanguage) {
+		t.Fatalf("expected ErrEmptyLanguage, got %v", err)
+	}
+}
+
+func TestCompleteIsTotal(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.AddSymbol('c')
+	b.On("A", 'a', "B").On("B", 'b', "A")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	cm, err := m.Complete("Sink")
+	if err != nil {
+		t.Fatalf("unexpected complete error: %v", err)
+	}
+	if cm.Accepting("Sink") {
+		t.Fatalf("sink must not be accepting")
+	}
+
+	rb := NewBuilder[string, rune](WithRequireTotalTransitions())
+	rb.SetInitial(cm.InitialState())
+	for _, sym := range cm.Symbols() {
+		rb.AddSymbol(sym)
+	}
+	for _, s := range cm.States() {
+		rb.AddState(s, cm.Accepting(s))
+		for _, sym := range cm.Symbols() {
+			if to, ok := cm.GetTransition(s, sym); ok {
+				rb.On(s, sym, to)
+			}
+		}
+	}
+	if _, err := rb.Build(); err != nil {
+		t.Fatalf("completed machine should pass totality check: %v", err)
+	}
+	if to, _ := cm.GetTransition("Sink", 'a'); to != "Sink" {
+		t.Fatalf("expected sink to self-loop, got %v", to)
+	}
+	assertSameLanguage(t, m, cm, []rune{'a', 'b', 'c'}, 5)
+}
+
+func TestCompleteSinkCollision(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("A")
+	b.AddState("B", true)
+	b.On("A", 'a', "B").On("A", 'b', "Trap").On("Trap", 'a', "Trap")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if _, err := m.Complete("A"); err == nil {
+		t.Fatalf("expected error when sink collides with a non-sink state")
+	}
+	if _, err := m.Complete("B"); err == nil {
+		t.Fatalf("expected error when sink is accepting")
+	}
+	cm, err := m.Complete("Trap")
+	if err != nil {
+		t.Fatalf("expected existing trap to be accepted as sink: %v", err)
+	}
+	if to, _ := cm.GetTransition("B", 'b'); to != "Trap" {
+		t.Fatalf("expected missing transition routed to Trap, got %v", to)
+	}
+}
+
+// dumpMachine renders a machine over int states in a canonical textual form.
+func dumpMachine(m *Machine[int, rune]) string {
+	var sb strings.Builder
+	n := len(m.States())
+	syms := m.sortedSymbols()
+	fmt.Fprintf(&sb, "initial=%d\n", m.InitialState())
+	for s := 0; s < n; s++ {
+		fmt.Fprintf(&sb, "%d accepting=%v", s, m.Accepting(s))
+		for _, sym := range syms {
+			if to, ok := m.GetTransition(s, sym); ok {
+				fmt.Fprintf(&sb, " %c->%d", sym, to)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func TestNormalizeDeterministic(t *testing.T) {
+	build := func(names [3]string) *Machine[string, rune] {
+		b := NewBuilder[string, rune]()
+		b.SetInitial(names[0])
+		b.AddState(names[0], true)
+		b.On(names[0], '0', names[0]).On(names[0], '1', names[1])
+		b.On(names[1], '0', names[2]).On(names[1], '1', names[0])
+		b.On(names[2], '0', names[1]).On(names[2], '1', names[2])
+		m, err := b.Build()
+		if err != nil {
+			t.Fatalf("unexpected build error: %v", err)
+		}
+		return m
+	}
+
+	want := "initial=0\n0 accepting=true 0->0 1->1\n1 accepting=false 0->2 1->0\n2 accepting=false 0->1 1->2\n"
+	for i := 0; i < 10; i++ {
+		a, rename := build([3]string{"S0", "S1", "S2"}).Normalize([]rune{'0', '1'})
+		if got := dumpMachine(a); got != want {
+			t.Fatalf("unexpected normalized form:\n%s", got)
+		}
+		if rename["S0"] != 0 || rename["S1"] != 1 || rename["S2"] != 2 {
+			t.Fatalf("unexpected rename map %v", rename)
+		}
+		// Same machine with entirely different labels normalizes identically.
+		b, _ := build([3]string{"zero", "one", "two"}).Normalize([]rune{'0', '1'})
+		if dumpMachine(b) != want {
+			t.Fatalf("relabelled machine normalized differently:\n%s", dumpMachine(b))
+		}
+	}
+}
+
+func TestNormalizeSymbolOrderAndUnreachable(t *testing.T) {
+	b := NewBuilder[string, rune]()
+	b.SetInitial("I")
+	b.On("I", 'a', "A").On("I", 'b', "B")
+	b.On("Z", 'a', "I")
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	_, rename := m.Normalize([]rune{'b', 'a'})
+	if rename["I"] != 0 || rename["B"] != 1 || rename["A"] != 2 || rename["Z"] != 3 {
+		t.Fatalf("unexpected rename map %v", rename)
+	}
+}
+
+func TestMapSymbolsEquivalence(t *testing.T) {
+	m := buildMod3(t)
+	bm, err := MapSymbols(m, func(r rune) byte { return byte(r) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range allStrings([]rune{'0', '1'}, 6) {
+		want, _ := m.EvalAccepting(w)
+		got, err := bm.EvalAccepting([]byte(string(w)))
+		if err != nil || got != want {
+			t.Fatalf("mismatch on %q: want %v, got %v, err: %v", string(w), want, got, err)
+		}
+	}
+}
+
+func TestMapSymbolsCollision(t *testing.T) {
+	m := buildMod3(t)
+	if _, err := MapSymbols(m, func(rune) string { return "bit" }); err == nil {
+		t.Fatalf("expected error when two symbols map to the same value")
+	}
+}
+
+func TestWithAccepting(t *testing.T) {
+	m := buildMod3(t)
+	derived, err := m.WithAccepting("S1", "S2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if derived.Accepting("S0") || !derived.Accepting("S1") || !derived.Accepting("S2") {
+		t.Fatalf("unexpected accepting set on derived machine")
+	}
+	if !m.Accepting("S0") || m.Accepting("S1") {
+		t.Fatalf("original machine was modified")
+	}
+	if !Equal(derived.WithAcceptingFunc(func(s string) bool { return s == "S0" }), m) {
+		t.Fatalf("expected WithAcceptingFunc to restore the original structure")
+	}
+	var unknown *UnknownStateError
+	if _, err := m.WithAccepting("S0", "S9"); !errors.As(err, &unknown) || unknown.State != "S9" {
+		t.Fatalf("expected UnknownStateError for S9, got %v", err)
+	}
+}
+
+func TestWithInitialMod3Rotations(t *testing.T) {
+	m := buildMod3(t)
+	for k, start := range []string{"S0", "S1", "S2"} {
+		rotated, err := m.WithInitial(start)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rotated.InitialState() != start || rotated.Start().State() != start {
+			t.Fatalf("expected derived machine to start in %s", start)
+		}
+		if got := rotated.States()[0]; got != start {
+			t.Fatalf("expected %s first in States, got %s", start, got)
+		}
+		// Starting in Sk means a prefix with remainder k was already consumed.
+		for n := 0; n < 32; n++ {
+			in := []rune(strconv.FormatInt(int64(n), 2))
+			want := (k<<len(in) + n) % 3
+			state, err := rotated.Eval(in)
+			if err != nil {
+				t.Fatalf("unexpected eval error: %v", err)
+			}
+			if state != fmt.Sprintf("S%d", want) {
+				t.Fatalf("start %s, input %s: expected S%d, got %s", start, string(in), want, state)
+			}
+			if ok, _ := rotated.EvalAccepting(in); ok != (want == 0) {
+				t.Fatalf("start %s, input %s: expected accepting=%v", start, string(in), want == 0)
+			}
+		}
+	}
+	if m.InitialState() != "S0" {
+		t.Fatalf("original initial state changed to %s", m.InitialState())
+	}
+	if _, err := m.WithInitial("S3"); err == nil {
+		t.Fatalf("expected error for unknown state")
+	}
+}
+
+func TestDerivedMachinesKeepNamers(t *testing.T) {
+	namer := func(v any) string {
+		c := v.(cell)
+		return fmt.Sprintf("r%dc%d", c.Row, c.Col)
+	}
+	symNamer := func(v any) string { return "<" + string(v.(rune)) + ">" }
+	start, goal := cell{0, 0, true}, cell{0, 1, true}
+	b := NewBuilder[cell, rune](WithStateNamer(namer), WithSymbolNamer(symNamer))
+	b.SetInitial(start).AddState(goal, true).On(start, 'e', goal)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	stepFromStart := func(d *Machine[cell, rune]) string {
+		return d.Start().Step('z').Error()
+	}
+	trimmed, _ := m.Trim()
+	accepting, _ := m.WithAccepting(start)
+	initial, _ := m.WithInitial(goal)
+	complete, _ := m.Complete(cell{9, 9, false})
+	for name, tc := range map[string]struct {
+		m    *Machine[cell, rune]
+		want string
+	}{
+		"Trim":              {trimmed, "no transition from r0c0 on <z>"},
+		"WithAccepting":     {accepting, "no transition from r0c0 on <z>"},
+		"WithAcceptingFunc": {m.WithAcceptingFunc(func(cell) bool { return true }), "no transition from r0c0 on <z>"},
+		"WithInitial":       {initial, "no transition from r0c1 on <z>"},
+		"Complete":          {complete, "no transition from r0c0 on <z>"},
+	} {
+		if got := stepFromStart(tc.m); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", name, tc.want, got)
+		}
+	}
+
+	mapped, err := MapSymbols(m, func(r rune) byte { return byte(r) })
+	if err != nil {
+		t.Fatalf("unexpected map error: %v", err)
+	}
+	if got, want := mapped.Start().Step('z').Error(), "no transition from r0c0 on 122"; got != want {
+		t.Errorf("MapSymbols: expected %q, got %q", want, got)
+	}
+	normalized, _ := m.Normalize(nil)
+	if got, want := normalized.Start().Step('z').Error(), "no transition from 0 on <z>"; got != want {
+		t.Errorf("Normalize: expected %q, got %q", want, got)
+	}
+	if got, want := m.Minimize().Start().Step('z').Error(), "no transition from 0 on <z>"; got != want {
+		t.Errorf("Minimize: expected %q, got %q", want, got)
+	}
+}